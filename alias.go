@@ -0,0 +1,109 @@
+package sparrow
+
+import (
+	"errors"
+	"github.com/xkgo/sparrow/logger"
+	"sort"
+	"strings"
+)
+
+/**
+注册别名，别名可以指向 beanName 或者另一个别名（别名链），按名称获取 Bean、@Inject:"name=..." 都可以使用别名，
+别名与已有的 beanName 冲突、重复注册到不同的名称或者形成环的话会直接 panic
+@param alias 别名，比如版本迁移之前的旧名称
+@param beanName 目标 beanName 或者别名
+*/
+func (a *Application) RegisterAlias(alias, beanName string) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.registerAlias(alias, beanName)
+}
+
+/**
+注册别名，调用方需要持有容器锁
+*/
+func (a *Application) registerAlias(alias, beanName string) {
+	var err error
+	if len(alias) < 1 || len(beanName) < 1 {
+		err = errors.New("别名以及beanName不能为空, alias: " + alias + ", beanName: " + beanName)
+	} else if alias == beanName {
+		err = errors.New("别名(" + alias + ")不能与beanName相同")
+	} else if bd, ok := a.container[alias]; ok {
+		err = errors.New("别名(" + alias + ")与已注册的Bean冲突：" + bd.Type.String())
+	} else if target, ok := a.aliases[alias]; ok && target != beanName {
+		err = errors.New("别名(" + alias + ")已经注册过了：" + strings.Join(a.aliasChain(alias), " -> "))
+	} else if chain := a.aliasChain(beanName); containsString(chain, alias) {
+		err = errors.New("别名(" + alias + ")形成了循环：" + alias + " -> " + strings.Join(chain, " -> "))
+	}
+	if err != nil {
+		logger.Error(err)
+		panic(err)
+	}
+
+	if a.aliases == nil {
+		a.aliases = make(map[string]string)
+	}
+	a.aliases[alias] = beanName
+}
+
+/**
+获取 beanName 的所有别名（包括间接指向的别名），按照名称排序
+*/
+func (a *Application) GetAliases(beanName string) []string {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	aliases := make([]string, 0)
+	canonical := a.canonicalBeanName(beanName)
+	for alias := range a.aliases {
+		if alias != beanName && a.canonicalBeanName(alias) == canonical {
+			aliases = append(aliases, alias)
+		}
+	}
+	sort.Strings(aliases)
+	return aliases
+}
+
+/**
+解析别名，返回最终的 beanName，不是别名的话直接返回，调用方需要持有容器锁
+*/
+func (a *Application) canonicalBeanName(name string) string {
+	chain := a.aliasChain(name)
+	return chain[len(chain)-1]
+}
+
+/**
+计算别名链，第一个元素是 name 本身，最后一个是最终的 beanName，如：old -> mid -> new，调用方需要持有容器锁
+*/
+func (a *Application) aliasChain(name string) []string {
+	chain := []string{name}
+	for {
+		target, ok := a.aliases[name]
+		if !ok || containsString(chain, target) {
+			return chain
+		}
+		chain = append(chain, target)
+		name = target
+	}
+}
+
+/**
+名称描述，别名的话带上别名链，用于错误信息
+*/
+func (a *Application) describeBeanName(name string) string {
+	a.lock.RLock()
+	chain := a.aliasChain(name)
+	a.lock.RUnlock()
+	if len(chain) < 2 {
+		return name
+	}
+	return name + "(别名: " + strings.Join(chain, " -> ") + ")"
+}
+
+func containsString(items []string, item string) bool {
+	for _, v := range items {
+		if v == item {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+package sparrow
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type LegacyOrderClient struct {
+	repo *OrderRepo `@Inject:"name=legacyOrderRepo,required=true"`
+}
+
+func TestApplication_RegisterAlias(t *testing.T) {
+	a := newTestApplication()
+	a.RegisterBean(&OrderRepo{table: "t_order"}, "orderRepository", true, WithAliases("orderRepo"))
+	a.RegisterAlias("legacyOrderRepo", "orderRepo")
+	a.RegisterBean(&LegacyOrderClient{}, "", true)
+
+	assert.Nil(t, a.init())
+
+	repo := a.GetBeanByName("orderRepository")
+	assert.NotNil(t, repo)
+	assert.Same(t, repo, a.GetBeanByName("orderRepo"))
+	assert.Same(t, repo, a.GetBeanByName("legacyOrderRepo"))
+	assert.Same(t, repo, a.GetBeanByName("LegacyOrderClient").(*LegacyOrderClient).repo)
+	assert.Equal(t, []string{"legacyOrderRepo", "orderRepo"}, a.GetAliases("orderRepository"))
+	assert.ElementsMatch(t, []string{"orderRepository", "orderRepo", "legacyOrderRepo", "LegacyOrderClient"}, a.GetBeanNames())
+}
+
+func TestApplication_RegisterAliasCollision(t *testing.T) {
+	a := newTestApplication()
+	a.RegisterBean(&OrderRepo{}, "orderRepo", true)
+	a.RegisterBean(&ConnPool{}, "connPool", true)
+	a.RegisterAlias("repo", "orderRepo")
+
+	assert.Panics(t, func() { a.RegisterAlias("connPool", "orderRepo") })
+	assert.Panics(t, func() { a.RegisterAlias("repo", "connPool") })
+	assert.Panics(t, func() { a.RegisterAlias("orderRepo", "repo") })
+	assert.Panics(t, func() { a.RegisterBean(&ConnPool{}, "repo", true) })
+	assert.NotPanics(t, func() { a.RegisterAlias("repo", "orderRepo") })
+}
+
+func TestApplication_AliasChainInError(t *testing.T) {
+	a := newTestApplication()
+	a.RegisterAlias("legacyOrderRepo", "orderRepo")
+	a.RegisterAlias("orderRepo", "orderRepository")
+	a.RegisterBean(&LegacyOrderClient{}, "", true)
+
+	err := a.init()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "legacyOrderRepo -> orderRepo -> orderRepository")
+}
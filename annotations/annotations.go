@@ -11,8 +11,10 @@ import (
 @Inject 注入注解
 */
 type InjectAnn struct {
-	Name     string `json:"name"`
-	Required bool   `json:"required"`
+	Name      string `json:"name"`
+	Required  bool   `json:"required"`
+	Qualifier string `json:"qualifier"` // 限定标签，只注入具有该标签的 Bean
+	Lazy      bool   `json:"lazy"`      // 延迟获取，字段类型为 func() T 或者 func() (T, error)，第一次调用的时候才会创建 Bean
 }
 
 func ScanInner(tag reflect.StructTag, annName, split string, consumer func(key, val string)) (exists bool, err error) {
@@ -46,7 +48,7 @@ func ScanInner(tag reflect.StructTag, annName, split string, consumer func(key,
 }
 
 /*
-@Inject 格式： name=value,required=true
+@Inject 格式： name=value,required=true,qualifier=primary,lazy=true
 */
 func FindInject(tag reflect.StructTag) (inject *InjectAnn, err error) {
 	inject = &InjectAnn{}
@@ -56,6 +58,10 @@ func FindInject(tag reflect.StructTag) (inject *InjectAnn, err error) {
 			inject.Name = val
 		case "required":
 			inject.Required = val == "true" || val == ""
+		case "qualifier":
+			inject.Qualifier = val
+		case "lazy":
+			inject.Lazy = val == "true" || val == ""
 		}
 	})
 	if err == nil && exists {
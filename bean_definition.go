@@ -2,7 +2,9 @@ package sparrow
 
 import (
 	"errors"
+	"github.com/xkgo/sparrow/util/ReflectUtils"
 	"reflect"
+	"time"
 )
 
 type BeanDefinition struct {
@@ -18,6 +20,13 @@ type BeanDefinition struct {
 	DestroyFn        reflect.Value // 初始化方法，无参函数
 	Ready            bool          // 是否已经准备好（已经初始化，并且成功执行了初始化方法）
 	Order            int64         // 排序，默认是0，越小优先级越低
+	Factory          reflect.Value // 工厂方法，func(deps...) (*T, error)，通过工厂方法注册的 Bean 在注入时才会创建
+	Scope            string        // 作用域，默认是 singleton
+	Conditions       []Condition   // 注册条件，全部满足才会注册
+	Qualifiers       []string      // 限定标签，@Inject 通过 qualifier 按照标签选择 Bean，如：primary、replica
+	Aliases          []string      // 别名，注册 Bean 的时候同时注册
+	Lazy             bool          // 延迟初始化，启动时不初始化，第一次被获取或者被注入的时候才初始化
+	InitDuration     time.Duration // Init 方法执行耗时
 }
 
 func newBeanDefinition(bean interface{}, beanName string, keyPrefix string, propertiesBean, changedListen, primary bool) *BeanDefinition {
@@ -28,6 +37,7 @@ func newBeanDefinition(bean interface{}, beanName string, keyPrefix string, prop
 		KeyPrefix:        keyPrefix,
 		IsPropertiesBean: propertiesBean,
 		ChangedListen:    changedListen,
+		Scope:            ScopeSingleton,
 	}
 
 	bd.Type = reflect.TypeOf(bean)
@@ -46,3 +56,67 @@ func newBeanDefinition(bean interface{}, beanName string, keyPrefix string, prop
 
 	return bd
 }
+
+/**
+通过工厂方法创建 BeanDefinition，工厂方法格式：func(deps...) *T 或者 func(deps...) (*T, error)
+*/
+func newFactoryBeanDefinition(fn interface{}, beanName string, primary bool) *BeanDefinition {
+	fv := reflect.ValueOf(fn)
+	if fv.Kind() != reflect.Func {
+		panic(errors.New("工厂方法必须是函数：func(deps...) (*T, error)"))
+	}
+	ft := fv.Type()
+	if ft.NumOut() < 1 || ft.NumOut() > 2 {
+		panic(errors.New("工厂方法返回值必须是 (*T) 或者 (*T, error)：" + ft.String()))
+	}
+	if ft.Out(0).Kind() != reflect.Ptr {
+		panic(errors.New("工厂方法第一个返回值必须是指针类型：" + ft.String()))
+	}
+	if ft.NumOut() == 2 && ft.Out(1) != ReflectUtils.ErrorType {
+		panic(errors.New("工厂方法第二个返回值必须是 error：" + ft.String()))
+	}
+
+	bd := &BeanDefinition{
+		Name:    beanName,
+		Primary: primary,
+		Type:    ft.Out(0),
+		Factory: fv,
+		Scope:   ScopeSingleton,
+	}
+	if len(bd.Name) < 1 {
+		bd.Name = bd.Type.Elem().Name()
+	}
+	return bd
+}
+
+/**
+是否是单例
+*/
+func (bd *BeanDefinition) IsSingleton() bool {
+	return len(bd.Scope) < 1 || bd.Scope == ScopeSingleton
+}
+
+/**
+是否具有指定的限定标签，qualifier 为空的话直接返回 true
+*/
+func (bd *BeanDefinition) HasQualifier(qualifier string) bool {
+	if len(qualifier) < 1 {
+		return true
+	}
+	for _, item := range bd.Qualifiers {
+		if item == qualifier {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+设置 Bean 实例，并重新计算初始化、销毁方法
+*/
+func (bd *BeanDefinition) setBean(bean reflect.Value) {
+	bd.Bean = bean.Interface()
+	bd.Value = bean
+	bd.InitFn = bean.MethodByName("Init")
+	bd.DestroyFn = bean.MethodByName("Destroy")
+}
@@ -0,0 +1,47 @@
+package sparrow
+
+/**
+注册 Bean 时候的选项
+*/
+type BeanOption func(bd *BeanDefinition)
+
+/**
+设置 Bean 的作用域，默认是 ScopeSingleton，参考 ScopePrototype 以及 RegisterScope 注册的自定义作用域
+*/
+func WithScope(scope string) BeanOption {
+	return func(bd *BeanDefinition) {
+		bd.Scope = scope
+	}
+}
+
+/**
+设置 Bean 的限定标签，同一个接口有多个实现的时候可以通过 @Inject:"qualifier=xxx" 按照标签选择
+*/
+func WithQualifiers(qualifiers ...string) BeanOption {
+	return func(bd *BeanDefinition) {
+		for _, qualifier := range qualifiers {
+			if len(qualifier) > 0 && !bd.HasQualifier(qualifier) {
+				bd.Qualifiers = append(bd.Qualifiers, qualifier)
+			}
+		}
+	}
+}
+
+/**
+注册 Bean 的同时注册别名，参考 RegisterAlias
+*/
+func WithAliases(aliases ...string) BeanOption {
+	return func(bd *BeanDefinition) {
+		bd.Aliases = append(bd.Aliases, aliases...)
+	}
+}
+
+/**
+延迟初始化，启动的时候不会创建以及初始化，第一次被获取或者被注入的时候才会初始化，
+只对单例 Bean 有效，适用于创建成本比较高但是不一定会用到的 Bean
+*/
+func WithLazy() BeanOption {
+	return func(bd *BeanDefinition) {
+		bd.Lazy = true
+	}
+}
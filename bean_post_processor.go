@@ -0,0 +1,82 @@
+package sparrow
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+)
+
+/**
+Bean 后置处理器，注册成 Bean 之后会对其他所有 Bean 生效（BeanPostProcessor 自身以及其依赖的 Bean 除外），
+可以用于校验必填属性、自动添加监控/耗时统计的包装、自动注册控制器等
+*/
+type BeanPostProcessor interface {
+	/**
+	属性注入完成之后，执行 Init 方法之前调用，返回 error 会导致程序启动失败
+	*/
+	BeforeInit(bd *BeanDefinition) error
+
+	/**
+	执行 Init 方法之后调用，返回非 nil 的对象会替换原来的 Bean，返回 nil 表示不替换。
+	替换对象（如：包装对象）通常和原来的 Bean 不是同一个类型，所以只能按照其实现的接口注入，
+	按照原来的具体类型（如：*UserService）注入会导致启动失败
+	*/
+	AfterInit(bd *BeanDefinition) (bean interface{}, err error)
+}
+
+var beanPostProcessorType = reflect.TypeOf((*BeanPostProcessor)(nil)).Elem()
+
+/**
+初始化容器中所有的 BeanPostProcessor，排序规则和 Slice 注入的一致
+*/
+func (a *Application) initBeanPostProcessors() (err error) {
+	bds := a.getBeanDefinitionsOfType(beanPostProcessorType)
+	if len(bds) < 1 {
+		return nil
+	}
+
+	bdList := make([]*BeanDefinition, 0, len(bds))
+	processors := make(map[*BeanDefinition]BeanPostProcessor)
+	for _, bd := range bds {
+		bean, err := a.getBeanInstance(bd, nil)
+		if err != nil {
+			return err
+		}
+		if processor, ok := bean.(BeanPostProcessor); ok {
+			bdList = append(bdList, bd)
+			processors[bd] = processor
+		}
+	}
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	sort.Slice(bdList, func(i, j int) bool {
+		return bdList[j].Order < bdList[i].Order
+	})
+
+	a.postProcessors = make([]BeanPostProcessor, 0, len(bdList))
+	for _, bd := range bdList {
+		a.postProcessors = append(a.postProcessors, processors[bd])
+	}
+	return nil
+}
+
+func (a *Application) getPostProcessors() []BeanPostProcessor {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	return a.postProcessors
+}
+
+/**
+Bean 被 BeanPostProcessor 替换之后按照原来的具体类型注入会转换失败，返回更明确的错误
+*/
+func replacedBeanError(bd *BeanDefinition, bean interface{}, targetType reflect.Type, err error) error {
+	if err == nil || bean == nil {
+		return err
+	}
+	if _, ok := bean.(reflect.Value); ok || reflect.TypeOf(bean) == bd.Type {
+		return err
+	}
+	return errors.New("Bean[" + bd.Name + "]已被 BeanPostProcessor 替换为 " + reflect.TypeOf(bean).String() +
+		"，只能按照其实现的接口注入，不能按照 " + targetType.String() + " 注入: " + err.Error())
+}
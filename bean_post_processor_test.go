@@ -0,0 +1,96 @@
+package sparrow
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type Greeter interface {
+	Greet(name string) string
+}
+
+type SimpleGreeter struct {
+	prefix string
+}
+
+func (g *SimpleGreeter) Greet(name string) string {
+	return g.prefix + name
+}
+
+type timedGreeter struct {
+	target Greeter
+	calls  int
+}
+
+func (g *timedGreeter) Greet(name string) string {
+	g.calls++
+	return g.target.Greet(name)
+}
+
+type GreeterClient struct {
+	greeter Greeter `@Inject:"required=true"`
+}
+
+type TimingPostProcessor struct {
+	before []string
+}
+
+func (p *TimingPostProcessor) BeforeInit(bd *BeanDefinition) error {
+	p.before = append(p.before, bd.Name)
+	if greeter, ok := bd.Bean.(*SimpleGreeter); ok && len(greeter.prefix) < 1 {
+		return errors.New("prefix is required")
+	}
+	return nil
+}
+
+func (p *TimingPostProcessor) AfterInit(bd *BeanDefinition) (bean interface{}, err error) {
+	if greeter, ok := bd.Bean.(Greeter); ok {
+		return &timedGreeter{target: greeter}, nil
+	}
+	return nil, nil
+}
+
+func TestApplication_BeanPostProcessor(t *testing.T) {
+	a := newTestApplication()
+	a.RegisterBean(&GreeterClient{}, "", true)
+	a.RegisterBean(&SimpleGreeter{prefix: "Hello, "}, "", true)
+	processor := &TimingPostProcessor{}
+	a.RegisterBean(processor, "", true)
+
+	assert.Nil(t, a.init())
+
+	client := a.GetBeanByName("GreeterClient").(*GreeterClient)
+	assert.Equal(t, "Hello, sparrow", client.greeter.Greet("sparrow"))
+
+	wrapper, ok := a.GetBeanByName("SimpleGreeter").(*timedGreeter)
+	assert.True(t, ok)
+	assert.Equal(t, 1, wrapper.calls)
+	assert.ElementsMatch(t, []string{"GreeterClient", "SimpleGreeter"}, processor.before)
+}
+
+func TestApplication_BeanPostProcessorBeforeInitError(t *testing.T) {
+	a := newTestApplication()
+	a.RegisterBean(&SimpleGreeter{}, "", true)
+	a.RegisterBean(&TimingPostProcessor{}, "", true)
+
+	err := a.init()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "prefix is required")
+}
+
+type ConcreteGreeterClient struct {
+	greeter *SimpleGreeter `@Inject:"required=true"`
+}
+
+func TestApplication_BeanPostProcessorReplaceConcreteInject(t *testing.T) {
+	a := newTestApplication()
+	a.RegisterBean(&ConcreteGreeterClient{}, "", true)
+	a.RegisterBean(&SimpleGreeter{prefix: "Hello, "}, "", true)
+	a.RegisterBean(&TimingPostProcessor{}, "", true)
+
+	// 替换对象只能按照接口注入
+	err := a.init()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "Bean[SimpleGreeter]已被 BeanPostProcessor 替换为 *sparrow.timedGreeter")
+}
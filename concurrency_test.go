@@ -0,0 +1,108 @@
+package sparrow
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+type ReportService struct {
+	repo *OrderRepo `@Inject:"required=true"`
+}
+
+func TestApplication_ConcurrentAccess(t *testing.T) {
+	searchClientCreated = 0
+	a := newTestApplication()
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			a.RegisterBean(&MysqlDataSource{url: strconv.Itoa(i)}, "dataSource"+strconv.Itoa(i), false)
+		}(i)
+	}
+	wg.Wait()
+	a.RegisterBean(&OrderRepo{table: "t_order"}, "orderRepo", true)
+	a.RegisterBean(&SearchClient{}, "searchClient", true, WithLazy())
+	assert.Nil(t, a.init())
+
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			name := "reportService" + strconv.Itoa(i)
+			a.RegisterBean(&ReportService{}, name, false)
+			report := a.GetBeanByName(name).(*ReportService)
+			assert.Same(t, a.GetBeanByName("orderRepo"), report.repo)
+			assert.NotNil(t, a.GetBeanByName("searchClient"))
+
+			_, err := a.GetBeanByType((*OrderRepo)(nil))
+			assert.Nil(t, err)
+			assert.Len(t, GetAll[DataSource](a), 10)
+			a.GetBeanNames()
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1, searchClientCreated)
+	assert.Len(t, GetAll[*ReportService](a), 20)
+}
+
+func TestApplication_RegisterAfterInitFailed(t *testing.T) {
+	a := newTestApplication()
+	assert.Nil(t, a.init())
+
+	assert.Panics(t, func() {
+		a.RegisterBean(&PoolClient{}, "poolClient", true, WithAliases("client"))
+	})
+	assert.Nil(t, a.GetBeanByName("poolClient"))
+	assert.Nil(t, a.GetBeanByName("client"))
+
+	a.RegisterBean(&ConnPool{}, "", true, OnProperty("sparrow.test.missing", "true"))
+	assert.Nil(t, a.GetBeanByName("ConnPool"))
+	assert.Len(t, a.GetConditionReport(), 1)
+}
+
+/**
+Init 方法中在其他 Goroutine 里获取 Bean 并等待结果
+*/
+type CacheWarmer struct {
+	repo   interface{}
+	search interface{}
+}
+
+func (w *CacheWarmer) Init(a *Application) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		w.repo = a.GetBeanByName("orderRepo")
+		w.search = a.GetBeanByName("searchClient")
+	}()
+	<-done
+}
+
+func TestApplication_InitGetBeanFromGoroutine(t *testing.T) {
+	searchClientCreated = 0
+	a := newTestApplication()
+	a.RegisterBean(&OrderRepo{table: "t_order"}, "orderRepo", true)
+	a.RegisterBean(&SearchClient{}, "searchClient", true, WithLazy())
+	a.RegisterBean(&CacheWarmer{}, "", true)
+
+	result := make(chan error, 1)
+	go func() {
+		result <- a.init()
+	}()
+	select {
+	case err := <-result:
+		assert.Nil(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Init 方法中在其他 Goroutine 获取 Bean 发生死锁")
+	}
+
+	warmer := a.GetBeanByName("CacheWarmer").(*CacheWarmer)
+	assert.Same(t, a.GetBeanByName("orderRepo"), warmer.repo)
+	assert.Same(t, a.GetBeanByName("searchClient"), warmer.search)
+	assert.Equal(t, 1, searchClientCreated)
+}
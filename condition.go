@@ -0,0 +1,245 @@
+package sparrow
+
+import (
+	"github.com/xkgo/sparrow/deploy"
+	"github.com/xkgo/sparrow/logger"
+	"reflect"
+	"strings"
+)
+
+/**
+Bean 注册条件，在容器初始化之前根据 Application.Environment 以及容器中的其他 Bean 进行判断，不满足的 Bean 不会注册到容器中
+*/
+type Condition interface {
+	/**
+	判断条件是否满足
+	*/
+	Matches(app *Application, bd *BeanDefinition) bool
+
+	/**
+	条件描述，用于输出条件报告
+	*/
+	String() string
+}
+
+/**
+依赖于容器中其他 Bean 的条件，会在其他条件都判断完之后再进行判断
+*/
+type BeanCondition interface {
+	Condition
+	IsBeanCondition() bool
+}
+
+/**
+Bean 的条件判断结果
+*/
+type ConditionEvaluation struct {
+	BeanName  string   // bean 名称
+	Matched   bool     // 是否满足所有条件
+	Passed    []string // 满足的条件
+	NotPassed []string // 不满足的条件
+}
+
+func (e *ConditionEvaluation) String() string {
+	status := "注册"
+	if !e.Matched {
+		status = "跳过"
+	}
+	return "[" + status + "] " + e.BeanName + ", 满足: " + strings.Join(e.Passed, ", ") + "; 不满足: " + strings.Join(e.NotPassed, ", ")
+}
+
+type condition struct {
+	description   string
+	beanCondition bool
+	matches       func(app *Application, bd *BeanDefinition) bool
+}
+
+func (c *condition) Matches(app *Application, bd *BeanDefinition) bool {
+	return c.matches(app, bd)
+}
+
+func (c *condition) String() string {
+	return c.description
+}
+
+func (c *condition) IsBeanCondition() bool {
+	return c.beanCondition
+}
+
+/**
+添加注册条件
+*/
+func WithConditions(conditions ...Condition) BeanOption {
+	return func(bd *BeanDefinition) {
+		bd.Conditions = append(bd.Conditions, conditions...)
+	}
+}
+
+/**
+配置项等于 havingValue 时注册，havingValue 为空的话，只要配置项存在并且不等于 false 即可
+*/
+func OnProperty(key, havingValue string) BeanOption {
+	return WithConditions(&condition{
+		description: "OnProperty(" + key + "=" + havingValue + ")",
+		matches: func(app *Application, bd *BeanDefinition) bool {
+			value, exists := app.Environment.GetProperty(key)
+			if !exists {
+				return false
+			}
+			if len(havingValue) < 1 {
+				return !strings.EqualFold(value, "false")
+			}
+			return value == havingValue
+		},
+	})
+}
+
+/**
+任意一个 profile 表达式满足时注册，和 ForProfiles 相同，支持 !prod、dev & wuxi 等表达式
+*/
+func OnProfile(profiles ...string) BeanOption {
+	return profileCondition("OnProfile", profiles)
+}
+
+/**
+当前部署环境是其中之一时注册
+*/
+func OnEnv(envs ...deploy.Env) BeanOption {
+	names := make([]string, 0, len(envs))
+	for _, env := range envs {
+		names = append(names, string(env))
+	}
+	return WithConditions(&condition{
+		description: "OnEnv(" + strings.Join(names, ",") + ")",
+		matches: func(app *Application, bd *BeanDefinition) bool {
+			current := app.Environment.GetEnv()
+			for _, env := range envs {
+				if env == current {
+					return true
+				}
+			}
+			return false
+		},
+	})
+}
+
+/**
+容器中存在指定类型的 Bean 时注册
+@param beanTemplate 类型模板，参考 GetBeanByType
+*/
+func OnBean(beanTemplate interface{}) BeanOption {
+	beanType := resolveBeanType(beanTemplate)
+	return WithConditions(&condition{
+		description:   "OnBean(" + beanType.String() + ")",
+		beanCondition: true,
+		matches: func(app *Application, bd *BeanDefinition) bool {
+			return app.existsOtherBeanOfType(beanType, bd)
+		},
+	})
+}
+
+/**
+容器中不存在指定类型的 Bean 时注册，一般用于提供允许用户覆盖的默认 Bean
+@param beanTemplate 类型模板，参考 GetBeanByType
+*/
+func OnMissingBean(beanTemplate interface{}) BeanOption {
+	beanType := resolveBeanType(beanTemplate)
+	return WithConditions(&condition{
+		description:   "OnMissingBean(" + beanType.String() + ")",
+		beanCondition: true,
+		matches: func(app *Application, bd *BeanDefinition) bool {
+			return !app.existsOtherBeanOfType(beanType, bd)
+		},
+	})
+}
+
+func (a *Application) existsOtherBeanOfType(beanType reflect.Type, self *BeanDefinition) bool {
+	for _, bd := range a.getBeanDefinitionsOfType(beanType) {
+		if bd != self {
+			return true
+		}
+	}
+	return false
+}
+
+func isBeanCondition(c Condition) bool {
+	if bc, ok := c.(BeanCondition); ok {
+		return bc.IsBeanCondition()
+	}
+	return false
+}
+
+/**
+执行条件判断，不满足条件的 Bean 从容器中移除（包括注册时设置的别名），先判断非 Bean 条件，然后再按照 Bean 名称顺序判断 Bean 条件
+*/
+func (a *Application) evaluateConditions() {
+	names := make([]string, 0)
+	bds := make(map[string]*BeanDefinition)
+	for _, bd := range a.beanDefinitions() {
+		if len(bd.Conditions) > 0 {
+			names = append(names, bd.Name)
+			bds[bd.Name] = bd
+		}
+	}
+	if len(names) < 1 {
+		return
+	}
+
+	evaluations := make(map[string]*ConditionEvaluation)
+	for _, beanCondition := range []bool{false, true} {
+		for _, name := range names {
+			bd := bds[name]
+			evaluation, ok := evaluations[name]
+			if ok && !evaluation.Matched {
+				continue
+			}
+			if !ok {
+				evaluation = &ConditionEvaluation{BeanName: name, Matched: true}
+				evaluations[name] = evaluation
+			}
+			a.matchConditions(evaluation, bd, beanCondition)
+			if !evaluation.Matched {
+				a.lock.Lock()
+				a.unregisterBeanDefinition(bd)
+				a.lock.Unlock()
+			}
+		}
+	}
+
+	report := make([]*ConditionEvaluation, 0, len(names))
+	lines := make([]string, 0, len(names))
+	for _, name := range names {
+		report = append(report, evaluations[name])
+		lines = append(lines, evaluations[name].String())
+	}
+	a.lock.Lock()
+	a.conditionReport = append(report, a.conditionReport...)
+	a.lock.Unlock()
+	logger.Info("条件注册报告：\n" + strings.Join(lines, "\n"))
+}
+
+/**
+检查 Bean 的注册条件，beanCondition 为 true 的话只检查 BeanCondition，否则只检查其他条件，结果记录到 evaluation 中
+*/
+func (a *Application) matchConditions(evaluation *ConditionEvaluation, bd *BeanDefinition, beanCondition bool) {
+	for _, c := range bd.Conditions {
+		if isBeanCondition(c) != beanCondition {
+			continue
+		}
+		if c.Matches(a, bd) {
+			evaluation.Passed = append(evaluation.Passed, c.String())
+		} else {
+			evaluation.NotPassed = append(evaluation.NotPassed, c.String())
+			evaluation.Matched = false
+		}
+	}
+}
+
+/**
+获取条件注册报告
+*/
+func (a *Application) GetConditionReport() []*ConditionEvaluation {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	return a.conditionReport
+}
@@ -0,0 +1,89 @@
+package sparrow
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/xkgo/sparrow/deploy"
+	"github.com/xkgo/sparrow/env"
+	"testing"
+)
+
+type Cache interface {
+	Get(key string) string
+}
+
+type MemoryCache struct {
+}
+
+func (m *MemoryCache) Get(key string) string {
+	return "memory:" + key
+}
+
+type RedisCache struct {
+}
+
+func (r *RedisCache) Get(key string) string {
+	return "redis:" + key
+}
+
+type CacheMetrics struct {
+}
+
+func TestApplication_Conditions(t *testing.T) {
+	a := NewApplication()
+	a.Environment = env.New(
+		env.ConfigDirs("./testdata"),
+		env.DeployInfo(&deploy.Info{Env: deploy.Dev}),
+		env.AppendCommandLine("--feature.redis.enabled=true"),
+	)
+
+	a.RegisterBean(&MemoryCache{}, "defaultCache", true, OnMissingBean((*Cache)(nil)))
+	a.RegisterBean(&RedisCache{}, "redisCache", true, OnProperty("feature.redis.enabled", "true"))
+	a.RegisterBean(&CacheMetrics{}, "cacheMetrics", true, OnBean((*Cache)(nil)), OnProfile("dev"))
+	a.RegisterBean(&ConnPool{}, "prodPool", true, OnEnv(deploy.Prod))
+
+	assert.Nil(t, a.init())
+
+	assert.Nil(t, a.GetBeanByName("defaultCache"))
+	assert.NotNil(t, a.GetBeanByName("redisCache"))
+	assert.NotNil(t, a.GetBeanByName("cacheMetrics"))
+	assert.Nil(t, a.GetBeanByName("prodPool"))
+
+	bean, err := a.GetBeanByType((*Cache)(nil))
+	assert.Nil(t, err)
+	assert.Equal(t, "redis:k", (*bean.(*Cache)).Get("k"))
+
+	report := a.GetConditionReport()
+	assert.Equal(t, 4, len(report))
+	for _, evaluation := range report {
+		switch evaluation.BeanName {
+		case "defaultCache", "prodPool":
+			assert.False(t, evaluation.Matched)
+		default:
+			assert.True(t, evaluation.Matched)
+		}
+	}
+}
+
+func TestApplication_ConditionOnMissingBeanDefault(t *testing.T) {
+	a := newTestApplication()
+	a.RegisterBean(&MemoryCache{}, "defaultCache", true, OnMissingBean((*Cache)(nil)))
+
+	assert.Nil(t, a.init())
+	assert.NotNil(t, a.GetBeanByName("defaultCache"))
+}
+
+func TestApplication_ConditionSkippedBeanAliases(t *testing.T) {
+	a := newTestApplication()
+	a.RegisterBean(&RedisCache{}, "redisCache", true, OnProperty("feature.redis.enabled", "true"), WithAliases("legacy"))
+
+	assert.Nil(t, a.init())
+	assert.Nil(t, a.GetBeanByName("legacy"))
+
+	// 别名随 Bean 一起移除，可以注册同名的 Bean
+	assert.NotPanics(t, func() {
+		a.RegisterBean(&MemoryCache{}, "legacy", true)
+	})
+	bean, ok := a.GetBeanByName("legacy").(*MemoryCache)
+	assert.True(t, ok)
+	assert.NotNil(t, bean)
+}
@@ -0,0 +1,145 @@
+package sparrow
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type CycleCache interface {
+	Get(key string) string
+}
+
+type CycleService struct {
+	repo *CycleRepo `@Inject:"required=true"`
+}
+
+type CycleRepo struct {
+	cache CycleCache `@Inject:"required=true"`
+}
+
+type CycleCacheImpl struct {
+	service *CycleService `@Inject:"required=true"`
+}
+
+func (c *CycleCacheImpl) Get(key string) string {
+	return key
+}
+
+type LazyCycleCacheImpl struct {
+	service func() *CycleService `@Inject:"required=true,lazy=true"`
+}
+
+func (c *LazyCycleCacheImpl) Get(key string) string {
+	return key
+}
+
+type InitCycleA struct {
+	b func() (*InitCycleB, error) `@Inject:"required=true,lazy=true"`
+}
+
+func (a *InitCycleA) Init() error {
+	_, err := a.b()
+	return err
+}
+
+type InitCycleB struct {
+	a func() (*InitCycleA, error) `@Inject:"required=true,lazy=true"`
+}
+
+func (b *InitCycleB) Init() error {
+	_, err := b.a()
+	return err
+}
+
+type PanicInitCycleA struct {
+	b func() *PanicInitCycleB `@Inject:"required=true,lazy=true"`
+}
+
+func (a *PanicInitCycleA) Init() {
+	a.b()
+}
+
+type PanicInitCycleB struct {
+	a func() *PanicInitCycleA `@Inject:"required=true,lazy=true"`
+}
+
+func (b *PanicInitCycleB) Init() {
+	b.a()
+}
+
+func TestApplication_CycleDependency(t *testing.T) {
+	a := newTestApplication()
+	a.RegisterBean(&CycleService{}, "service", true)
+	a.RegisterBean(&CycleRepo{}, "repo", true)
+	a.RegisterBean(&CycleCacheImpl{}, "cache", true)
+
+	err := a.init()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "service.repo -> repo")
+	assert.Contains(t, err.Error(), "repo.cache -> cache")
+	assert.Contains(t, err.Error(), "cache.service -> service")
+}
+
+func TestApplication_LazyBreaksCycle(t *testing.T) {
+	a := newTestApplication()
+	a.RegisterBean(&CycleService{}, "service", true)
+	a.RegisterBean(&CycleRepo{}, "repo", true)
+	a.RegisterBean(&LazyCycleCacheImpl{}, "cache", true)
+
+	assert.Nil(t, a.init())
+	cache := a.GetBeanByName("cache").(*LazyCycleCacheImpl)
+	assert.Same(t, a.GetBeanByName("service"), cache.service())
+}
+
+func TestApplication_SameTypeIsNotCycle(t *testing.T) {
+	a := newTestApplication()
+	a.RegisterBean(&MysqlDataSource{url: "master"}, "masterDataSource", true)
+	a.RegisterFactory(func(ds DataSource) *MysqlDataSource {
+		return &MysqlDataSource{url: "proxy:" + ds.Url()}
+	}, "proxyDataSource", false)
+
+	assert.Nil(t, a.init())
+	assert.Equal(t, "proxy:master", a.GetBeanByName("proxyDataSource").(*MysqlDataSource).url)
+}
+
+func TestApplication_LazyCycleInInit(t *testing.T) {
+	a := newTestApplication()
+	a.RegisterBean(&InitCycleA{}, "initCycleA", true)
+	a.RegisterBean(&InitCycleB{}, "initCycleB", true)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- a.init()
+	}()
+	select {
+	case err := <-done:
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "initCycleA.b -> initCycleB.a -> initCycleA")
+	case <-time.After(5 * time.Second):
+		t.Fatal("Init 方法中通过延迟注入互相获取没有返回循环依赖错误")
+	}
+}
+
+func TestApplication_LazyCycleInInitPanics(t *testing.T) {
+	a := newTestApplication()
+	a.RegisterBean(&PanicInitCycleA{}, "panicInitCycleA", true)
+	a.RegisterBean(&PanicInitCycleB{}, "panicInitCycleB", true)
+
+	done := make(chan interface{}, 1)
+	go func() {
+		// func() T 类型的延迟注入获取失败直接 panic
+		defer func() {
+			done <- recover()
+		}()
+		_ = a.init()
+	}()
+	select {
+	case r := <-done:
+		err, ok := r.(error)
+		assert.True(t, ok)
+		assert.Contains(t, err.Error(), "panicInitCycleA.b -> panicInitCycleB.a -> panicInitCycleA")
+	case <-time.After(5 * time.Second):
+		t.Fatal("Init 方法中通过延迟注入互相获取没有返回循环依赖错误")
+	}
+}
@@ -0,0 +1,60 @@
+package env
+
+import (
+	"errors"
+	"reflect"
+	"sync/atomic"
+)
+
+/**
+绑定配置到 T 类型的对象，等价于 BindProperties(prefix, new(T))，不监听配置变化
+@param prefix key前缀，会直接拼接，如：server.
+*/
+func Bind[T any](environment Environment, prefix string) (cfg *T, err error) {
+	return bind[T](environment, prefix, false)
+}
+
+/**
+绑定配置到 T 类型的对象，并且监听配置的变化，同一个类型只会绑定一次，
+配置变更之后会创建新的 T 对象并原子替换，通过返回的 Properties.Get() 读取最新的配置
+@param prefix key前缀，会直接拼接，如：server.
+*/
+func BindListen[T any](environment Environment, prefix string) (cfg *Properties[T], err error) {
+	bean, err := bind[T](environment, prefix, true)
+	if err != nil {
+		return nil, err
+	}
+	if s, ok := environment.(*StandardEnvironment); ok {
+		if current := s.propertiesSnapshot(reflect.TypeOf(bean).Elem()); current != nil {
+			return &Properties[T]{current: current}, nil
+		}
+	}
+	current := &atomic.Value{}
+	current.Store(bean)
+	return &Properties[T]{current: current}, nil
+}
+
+/**
+监听配置变化的配置，每次变更都会发布新的对象，已经发布的对象不会再被修改，可以并发读取
+*/
+type Properties[T any] struct {
+	current *atomic.Value
+}
+
+/**
+获取最新的配置，返回的对象是只读的，不要修改
+*/
+func (p *Properties[T]) Get() *T {
+	return p.current.Load().(*T)
+}
+
+func bind[T any](environment Environment, prefix string, listen bool) (cfg *T, err error) {
+	if reflect.TypeOf((*T)(nil)).Elem().Kind() != reflect.Struct {
+		return nil, errors.New("配置绑定类型必须是结构体: " + reflect.TypeOf((*T)(nil)).Elem().String())
+	}
+	bean, err := environment.BindPropertiesListen(prefix, new(T), listen)
+	if err != nil {
+		return nil, err
+	}
+	return bean.(*T), nil
+}
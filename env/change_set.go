@@ -0,0 +1,78 @@
+package env
+
+import (
+	"regexp"
+	"strings"
+)
+
+/**
+一次配置刷新产生的所有变更，比如 PollingPropertySource 一次 Reload 的结果，按照 key 排序
+*/
+type ChangeSet struct {
+	Source  string            // 配置来源名称
+	Changes []*KeyChangeEvent // 变更列表
+}
+
+/**
+变更的所有 key
+*/
+func (c *ChangeSet) Keys() []string {
+	keys := make([]string, 0, len(c.Changes))
+	for _, change := range c.Changes {
+		keys = append(keys, change.Key)
+	}
+	return keys
+}
+
+/**
+获取以 prefix 开头的变更，prefix 为空的话返回所有变更
+*/
+func (c *ChangeSet) WithPrefix(prefix string) []*KeyChangeEvent {
+	changes := make([]*KeyChangeEvent, 0)
+	for _, change := range c.Changes {
+		if strings.HasPrefix(change.Key, prefix) {
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+/**
+获取 key 匹配 keyPattern 的变更，匹配规则和 Subscribe 的一致
+*/
+func (c *ChangeSet) Matches(keyPattern string) []*KeyChangeEvent {
+	changes := make([]*KeyChangeEvent, 0)
+	var regex *regexp.Regexp
+	if keyPattern != "" && keyPattern != "*" {
+		regex, _ = regexp.Compile(keyPattern)
+	}
+	for _, change := range c.Changes {
+		if keyPattern == "" || keyPattern == "*" || keyPattern == change.Key || (regex != nil && regex.MatchString(change.Key)) {
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+func (c *ChangeSet) String() string {
+	items := make([]string, 0, len(c.Changes))
+	for _, change := range c.Changes {
+		items = append(items, change.String())
+	}
+	return "ChangeSet[" + c.Source + "]{" + strings.Join(items, ", ") + "}"
+}
+
+/**
+支持批量发布变更的配置来源，一次刷新的所有变更作为一个 ChangeSet 发布，
+没有实现这个接口的配置来源，每个 KeyChangeEvent 会作为只有一个变更的 ChangeSet
+*/
+type ChangeSetPublisher interface {
+	SubscribeChangeSet(handler func(changes *ChangeSet))
+}
+
+/**
+配置Bean可以实现这个接口，配置Bean整体更新之后回调，old、new 为更新前后的配置快照（不会再被修改），changes 为相关的变更
+*/
+type PropertiesChangedListener interface {
+	OnPropertiesChanged(old, new interface{}, changes []*KeyChangeEvent)
+}
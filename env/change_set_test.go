@@ -0,0 +1,153 @@
+package env
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+type DbPoolConfig struct {
+	sync.RWMutex
+	Host string `ck:"host" validate:"required"`
+	Port int    `ck:"port" validate:"min=1"`
+
+	changed [][2]*DbPoolConfig
+	changes [][]*KeyChangeEvent
+}
+
+func (c *DbPoolConfig) OnPropertiesChanged(old, new interface{}, changes []*KeyChangeEvent) {
+	c.changed = append(c.changed, [2]*DbPoolConfig{old.(*DbPoolConfig), new.(*DbPoolConfig)})
+	c.changes = append(c.changes, changes)
+}
+
+func (c *DbPoolConfig) Address() string {
+	c.RLock()
+	defer c.RUnlock()
+	return c.Host + ":" + strconv.Itoa(c.Port)
+}
+
+func TestStandardEnvironment_ChangeSet(t *testing.T) {
+	properties := map[string]string{"db.host": "10.0.0.1", "db.port": "3306", "other": "1"}
+	var mu sync.Mutex
+	reader := NewPropertyReader(func() (kvs map[string]string, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		kvs = make(map[string]string)
+		for k, v := range properties {
+			kvs[k] = v
+		}
+		return kvs, nil
+	})
+	source, _ := NewPollingPropertySource("db", 0, reader)
+	env := New(AdditionalPropertySources(NewMutablePropertySources(source)))
+
+	changeSets := make([]*ChangeSet, 0)
+	env.SubscribeChangeSet(func(changes *ChangeSet) {
+		changeSets = append(changeSets, changes)
+	})
+	snapshot, err := BindListen[DbPoolConfig](env, "db.")
+	assert.Nil(t, err)
+	cfg := env.GetProperties(&DbPoolConfig{}).(*DbPoolConfig)
+	assert.Equal(t, "10.0.0.1:3306", cfg.Address())
+	assert.Equal(t, "10.0.0.1:3306", snapshot.Get().Address())
+
+	mu.Lock()
+	properties["db.host"] = "10.0.0.2"
+	properties["db.port"] = "3307"
+	properties["other"] = "2"
+	mu.Unlock()
+	assert.Nil(t, source.Reload())
+
+	assert.Len(t, changeSets, 1)
+	assert.Equal(t, "db", changeSets[0].Source)
+	assert.Equal(t, []string{"db.host", "db.port", "other"}, changeSets[0].Keys())
+	assert.Equal(t, "10.0.0.2:3307", cfg.Address())
+	assert.Equal(t, "10.0.0.2:3307", snapshot.Get().Address())
+	assert.Len(t, cfg.changed, 1)
+	assert.Equal(t, "10.0.0.1:3306", cfg.changed[0][0].Address())
+	assert.Equal(t, "10.0.0.2:3307", cfg.changed[0][1].Address())
+	assert.Len(t, cfg.changes[0], 2)
+
+	// 只变更了其他配置，不会回调
+	mu.Lock()
+	properties["other"] = "3"
+	mu.Unlock()
+	assert.Nil(t, source.Reload())
+	assert.Len(t, cfg.changed, 1)
+
+	// 读取方不会看到新的 host 和旧的 port
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 1000; i++ {
+			address := cfg.Address()
+			assert.True(t, address == "10.0.0.2:3307" || address == "10.0.0.3:3308", address)
+		}
+	}()
+	for i := 0; i < 20; i++ {
+		mu.Lock()
+		if i%2 == 0 {
+			properties["db.host"], properties["db.port"] = "10.0.0.3", "3308"
+		} else {
+			properties["db.host"], properties["db.port"] = "10.0.0.2", "3307"
+		}
+		mu.Unlock()
+		assert.Nil(t, source.Reload())
+	}
+	<-done
+}
+
+type PlainPoolConfig struct {
+	Host string `ck:"host"`
+	Port int    `ck:"port"`
+}
+
+func TestStandardEnvironment_PropertiesSnapshot(t *testing.T) {
+	properties := map[string]string{"db.host": "10.0.0.1", "db.port": "3306"}
+	var mu sync.Mutex
+	reader := NewPropertyReader(func() (kvs map[string]string, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		kvs = make(map[string]string)
+		for k, v := range properties {
+			kvs[k] = v
+		}
+		return kvs, nil
+	})
+	source, _ := NewPollingPropertySource("db", 0, reader)
+	env := New(AdditionalPropertySources(NewMutablePropertySources(source)))
+
+	snapshot, err := BindListen[PlainPoolConfig](env, "db.")
+	assert.Nil(t, err)
+	bean := env.GetProperties(&PlainPoolConfig{}).(*PlainPoolConfig)
+	first := snapshot.Get()
+	assert.Equal(t, PlainPoolConfig{Host: "10.0.0.1", Port: 3306}, *first)
+
+	// 每次变更发布新的快照，读取方不会看到新的 host 和旧的 port
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 1000; i++ {
+			cfg := snapshot.Get()
+			address := cfg.Host + ":" + strconv.Itoa(cfg.Port)
+			assert.True(t, address == "10.0.0.1:3306" || address == "10.0.0.2:3307" || address == "10.0.0.3:3308", address)
+		}
+	}()
+	for i := 0; i < 20; i++ {
+		mu.Lock()
+		if i%2 == 0 {
+			properties["db.host"], properties["db.port"] = "10.0.0.3", "3308"
+		} else {
+			properties["db.host"], properties["db.port"] = "10.0.0.2", "3307"
+		}
+		mu.Unlock()
+		assert.Nil(t, source.Reload())
+	}
+	<-done
+
+	assert.Equal(t, PlainPoolConfig{Host: "10.0.0.2", Port: 3307}, *snapshot.Get())
+	// 已经发布的快照不会被修改，没有实现 sync.Locker 的配置Bean同样会更新
+	assert.Equal(t, PlainPoolConfig{Host: "10.0.0.1", Port: 3306}, *first)
+	assert.Equal(t, PlainPoolConfig{Host: "10.0.0.2", Port: 3307}, *bean)
+}
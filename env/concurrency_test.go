@@ -0,0 +1,175 @@
+package env
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+/**
+版本号递增的配置读取，同一个版本的所有配置项值都相同
+*/
+type versionedReader struct {
+	version int64
+}
+
+func (r *versionedReader) ReadAll() (kvs map[string]string, err error) {
+	version := strconv.FormatInt(atomic.AddInt64(&r.version, 1), 10)
+	return map[string]string{"app.a": version, "app.b": version, "app.c": version}, nil
+}
+
+func TestPollingPropertySource_ConcurrentReload(t *testing.T) {
+	source, _ := NewPollingPropertySource("versioned", 0, &versionedReader{})
+
+	var changeSets int64
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				// 同一次遍历读取到的是同一个快照
+				values := make(map[string]bool)
+				source.Each(func(key string, value string) (stop bool) {
+					values[value] = true
+					return false
+				})
+				assert.Len(t, values, 1)
+				_, exists := source.GetProperty("app.a")
+				assert.True(t, exists)
+			}
+		}()
+	}
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				source.Subscribe("app.a", func(event *KeyChangeEvent) {})
+				source.SubscribeChangeSet(func(changes *ChangeSet) {
+					atomic.AddInt64(&changeSets, 1)
+				})
+			}
+		}()
+	}
+	for i := 0; i < 200; i++ {
+		assert.Nil(t, source.Reload())
+	}
+	close(stop)
+	wg.Wait()
+
+	assert.Nil(t, source.Reload())
+	assert.True(t, atomic.LoadInt64(&changeSets) >= 200)
+}
+
+func TestMutablePropertySources_Concurrent(t *testing.T) {
+	sources := NewMutablePropertySources(NewMapPropertySource("first", map[string]string{"k": "first"}))
+
+	var notified int64
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			name := "source-" + strconv.Itoa(i)
+			for j := 0; j < 100; j++ {
+				sources.AddLast(NewMapPropertySource(name, map[string]string{"k": name}))
+				_ = sources.Replace(name, NewMapPropertySource(name, map[string]string{"k": name}))
+				_ = sources.AddBefore("first", NewMapPropertySource(name+"-before", nil))
+				sources.Remove(name + "-before")
+				sources.Subscribe(func(self *MutablePropertySources) {
+					atomic.AddInt64(&notified, 1)
+				})
+			}
+		}(i)
+	}
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 500; j++ {
+				names := make(map[string]bool)
+				sources.Each(func(index int, source PropertySource) (stop bool) {
+					assert.False(t, names[source.GetName()], "重复的配置来源: "+source.GetName())
+					names[source.GetName()] = true
+					return false
+				})
+				assert.True(t, sources.Contains("first"))
+				if source, ok := sources.Get("first"); assert.True(t, ok) {
+					assert.Equal(t, "first", source.GetPropertyWithDef("k", ""))
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 5, sources.Size())
+	assert.True(t, atomic.LoadInt64(&notified) > 0)
+}
+
+func TestStandardEnvironment_ConcurrentSubscribe(t *testing.T) {
+	source, _ := NewPollingPropertySource("versioned", 0, &versionedReader{})
+	env := New(AdditionalPropertySources(NewMutablePropertySources(source)))
+	parent := New(IncludeProfiles("concurrent"))
+
+	var events, changeSets int64
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				env.Subscribe("app\\..*", func(event *KeyChangeEvent) {
+					atomic.AddInt64(&events, 1)
+				})
+				env.SubscribeChangeSet(func(changes *ChangeSet) {
+					atomic.AddInt64(&changeSets, 1)
+				})
+			}
+		}()
+	}
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				_, exists := env.GetProperty("app.a")
+				assert.True(t, exists)
+				_ = env.ResolvePlaceholders("${app.b}")
+				_ = env.GetActiveProfiles()
+				_ = env.GetProperties(&DbPoolConfig{})
+			}
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		env.Merge(parent)
+		_, err := BindListen[DbPoolConfig](env, "db.")
+		assert.NotNil(t, err)
+	}()
+	for i := 0; i < 100; i++ {
+		assert.Nil(t, source.Reload())
+	}
+	close(stop)
+	wg.Wait()
+
+	assert.Nil(t, source.Reload())
+	assert.True(t, atomic.LoadInt64(&changeSets) > 0)
+	assert.True(t, atomic.LoadInt64(&events) > 0)
+	assert.Contains(t, env.GetActiveProfiles(), "concurrent")
+}
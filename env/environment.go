@@ -28,6 +28,11 @@ type Environment interface {
 	*/
 	Subscribe(keyPattern string, handler func(event *KeyChangeEvent))
 
+	/**
+	订阅批量变更，一次配置刷新的所有变更作为一个 ChangeSet，配置Bean已经完成整体刷新之后才会回调
+	*/
+	SubscribeChangeSet(handler func(changes *ChangeSet))
+
 	/**
 	绑定配置项到某个模型对象，注意传进来的必须是指针类型, keyPrefix key前缀，会直接和配置struct的属性直接拼接，如果有.的话要注意了
 	@param name 名称，唯一
@@ -228,6 +228,11 @@ func ReadLocalFileAsPropertySource(name string, path string) (propertySource Pro
 
 		keys := v.AllKeys()
 		for _, key := range keys {
+			if list, ok := v.Get(key).([]interface{}); ok {
+				// 列表展开成 key[0]、key[1].xxx 的形式
+				flattenPropertyValue(props, key, list)
+				continue
+			}
 			value := v.GetString(key)
 			props[key] = value
 		}
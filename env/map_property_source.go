@@ -20,13 +20,7 @@ func (m *MapPropertySource) GetName() string {
 }
 
 func (m *MapPropertySource) GetProperty(key string) (value string, exists bool) {
-	if nil == m.properties {
-		m.properties = make(map[string]string)
-		return "", false
-	}
-	if len(m.properties) < 1 {
-		return "", false
-	}
+	// 只读，nil map 也可以直接读取
 	value, exists = m.properties[key]
 	return
 }
@@ -3,6 +3,8 @@ package env
 import (
 	"errors"
 	"github.com/xkgo/sparrow/logger"
+	"sync"
+	"sync/atomic"
 )
 
 type PropertySources interface {
@@ -26,43 +28,49 @@ type PropertySources interface {
 }
 
 /**
-可变配置来源
+可变配置来源，配置来源列表是只读快照，修改时复制一份新的列表然后整体替换，遍历时不需要加锁
 */
 type MutablePropertySources struct {
 	/**
-	配置来源列表，左边的优先生效，比如同一个key在 第一第二个元素上面都存在，那么则会优先使用第一个元素上面的值，
+	配置来源列表（[]PropertySource），左边的优先生效，比如同一个key在 第一第二个元素上面都存在，那么则会优先使用第一个元素上面的值，
 	不管第一个是否为空字符串都要以第一个元素为准
 	*/
-	propertySourceList []PropertySource // 配置来源列表
+	propertySourceList atomic.Value // 配置来源列表
+
+	lock         sync.Mutex   // 串行修改配置来源列表
+	listenerLock sync.RWMutex // 保护监听器列表
 
 	// 监听器
 	listeners []func(self *MutablePropertySources)
 }
 
 func NewMutablePropertySources(propertySourceList ...PropertySource) *MutablePropertySources {
-	if len(propertySourceList) < 1 {
-		propertySourceList = make([]PropertySource, 0)
-	}
-	return &MutablePropertySources{propertySourceList: propertySourceList}
+	list := make([]PropertySource, 0, len(propertySourceList))
+	list = append(list, propertySourceList...)
+	sources := &MutablePropertySources{}
+	sources.propertySourceList.Store(list)
+	return sources
 }
 
 func (s *MutablePropertySources) Subscribe(listener func(self *MutablePropertySources)) {
-	if s.listeners == nil {
-		s.listeners = make([]func(self *MutablePropertySources), 0)
-	}
-	s.listeners = append(s.listeners, listener)
+	s.listenerLock.Lock()
+	defer s.listenerLock.Unlock()
+	listeners := make([]func(self *MutablePropertySources), 0, len(s.listeners)+1)
+	listeners = append(listeners, s.listeners...)
+	s.listeners = append(listeners, listener)
 }
 
 func (s *MutablePropertySources) onPropertySourceChanged() {
-	if len(s.listeners) > 0 {
-		for _, listener := range s.listeners {
-			listener(s)
-		}
+	s.listenerLock.RLock()
+	listeners := s.listeners
+	s.listenerLock.RUnlock()
+	for _, listener := range listeners {
+		listener(s)
 	}
 }
 
 func (s *MutablePropertySources) Contains(name string) bool {
-	_, err := s.assertPresentAndGetIndex(name)
+	_, err := assertPresentAndGetIndex(s.getPropertySourceList(), name)
 	if err != nil {
 		return false
 	}
@@ -70,11 +78,12 @@ func (s *MutablePropertySources) Contains(name string) bool {
 }
 
 func (s *MutablePropertySources) Get(name string) (source PropertySource, exists bool) {
-	index, err := s.assertPresentAndGetIndex(name)
+	list := s.getPropertySourceList()
+	index, err := assertPresentAndGetIndex(list, name)
 	if err != nil {
 		return nil, false
 	}
-	return s.propertySourceList[index], true
+	return list[index], true
 }
 
 func (s *MutablePropertySources) Each(consumer func(index int, source PropertySource) (stop bool)) {
@@ -93,16 +102,11 @@ func (s *MutablePropertySources) AddFirst(propertySource PropertySource) {
 	if logger.IsDebugEnabled() {
 		logger.Debug("Adding PropertySource '" + propertySource.GetName() + "' with highest search precedence")
 	}
-	// 如果已经存在，那么删除
-	s.removeIfPresent(propertySource.GetName())
-	// 添加到第一个元素
-	list := s.getPropertySourceList()
-	newList := make([]PropertySource, 0)
-	newList = append(newList, propertySource)
-	newList = append(newList, list...)
-	s.propertySourceList = newList
-
-	s.onPropertySourceChanged()
+	_ = s.update(func(list []PropertySource) ([]PropertySource, error) {
+		// 如果已经存在，那么删除，然后添加到第一个元素
+		list = removeIfPresent(list, propertySource.GetName())
+		return append([]PropertySource{propertySource}, list...), nil
+	})
 }
 
 /**
@@ -112,13 +116,11 @@ func (s *MutablePropertySources) AddLast(propertySource PropertySource) {
 	if logger.IsDebugEnabled() {
 		logger.Debug("Adding PropertySource '" + propertySource.GetName() + "' with lowest search precedence")
 	}
-	s.removeIfPresent(propertySource.GetName())
-	// 添加到最后
-	list := s.getPropertySourceList()
-	list = append(list, propertySource)
-	s.propertySourceList = list
-
-	s.onPropertySourceChanged()
+	_ = s.update(func(list []PropertySource) ([]PropertySource, error) {
+		// 添加到最后
+		list = removeIfPresent(list, propertySource.GetName())
+		return append(list, propertySource), nil
+	})
 }
 
 /**
@@ -133,30 +135,15 @@ func (s *MutablePropertySources) AddBefore(relativePropertySourceName string, pr
 	if relativePropertySourceName == propertySource.GetName() {
 		return errors.New("PropertySource named '" + relativePropertySourceName + "' cannot be added relative to itself")
 	}
-	s.removeIfPresent(propertySource.GetName())
-
-	list := s.getPropertySourceList()
-	// 检查要插入到之前的那个配置来源是否存在
-	index, err := s.assertPresentAndGetIndex(relativePropertySourceName)
-	if err != nil {
-		return err
-	}
-
-	// 添加到之前
-	leftList := list[0:index]
-	rightList := list[index:]
-
-	newList := make([]PropertySource, 0)
-	if len(leftList) > 0 {
-		newList = append(newList, leftList...)
-	}
-	newList = append(newList, propertySource)
-	if len(rightList) > 0 {
-		newList = append(newList, rightList...)
-	}
-	s.propertySourceList = newList
-	s.onPropertySourceChanged()
-	return nil
+	return s.update(func(list []PropertySource) ([]PropertySource, error) {
+		list = removeIfPresent(list, propertySource.GetName())
+		// 检查要插入到之前的那个配置来源是否存在
+		index, err := assertPresentAndGetIndex(list, relativePropertySourceName)
+		if err != nil {
+			return nil, err
+		}
+		return insertAt(list, index, propertySource), nil
+	})
 }
 
 func (s *MutablePropertySources) AddAfter(relativePropertySourceName string, propertySource PropertySource) error {
@@ -167,93 +154,93 @@ func (s *MutablePropertySources) AddAfter(relativePropertySourceName string, pro
 	if relativePropertySourceName == propertySource.GetName() {
 		return errors.New("PropertySource named '" + relativePropertySourceName + "' cannot be added relative to itself")
 	}
-
-	s.removeIfPresent(propertySource.GetName())
-
-	list := s.getPropertySourceList()
-	// 检查要插入到之前的那个配置来源是否存在
-	index, err := s.assertPresentAndGetIndex(relativePropertySourceName)
-	if err != nil {
-		return err
-	}
-	if index == len(list)-1 {
-		// 刚好最后一个
-		s.propertySourceList = append(list, propertySource)
-		return nil
-	}
-
-	// 添加到之后
-	leftList := list[0 : index+1]
-	rightList := list[index+1:]
-
-	newList := make([]PropertySource, 0)
-	if len(leftList) > 0 {
-		newList = append(newList, leftList...)
-	}
-	newList = append(newList, propertySource)
-	if len(rightList) > 0 {
-		newList = append(newList, rightList...)
-	}
-	s.propertySourceList = newList
-
-	s.onPropertySourceChanged()
-	return nil
+	return s.update(func(list []PropertySource) ([]PropertySource, error) {
+		list = removeIfPresent(list, propertySource.GetName())
+		// 检查要插入到之后的那个配置来源是否存在
+		index, err := assertPresentAndGetIndex(list, relativePropertySourceName)
+		if err != nil {
+			return nil, err
+		}
+		return insertAt(list, index+1, propertySource), nil
+	})
 }
 
 func (s *MutablePropertySources) Replace(name string, propertySource PropertySource) error {
 	if logger.IsDebugEnabled() {
 		logger.Debug("Replacing PropertySource '" + name + "' with '" + propertySource.GetName() + "'")
 	}
-
-	index, err := s.assertPresentAndGetIndex(name)
-	if err != nil {
-		return err
-	}
-	// 直接替换
-	s.propertySourceList[index] = propertySource
-
-	s.onPropertySourceChanged()
-	return nil
+	return s.update(func(list []PropertySource) ([]PropertySource, error) {
+		index, err := assertPresentAndGetIndex(list, name)
+		if err != nil {
+			return nil, err
+		}
+		// 直接替换
+		newList := append(make([]PropertySource, 0, len(list)), list...)
+		newList[index] = propertySource
+		return newList, nil
+	})
 }
 
 func (s *MutablePropertySources) Remove(name string) {
-	s.removeIfPresent(name)
-	s.onPropertySourceChanged()
+	_ = s.update(func(list []PropertySource) ([]PropertySource, error) {
+		return removeIfPresent(list, name), nil
+	})
 }
 
 func (s *MutablePropertySources) Size() int {
 	return len(s.getPropertySourceList())
 }
 
+/**
+当前的配置来源列表快照，只读
+*/
 func (s *MutablePropertySources) getPropertySourceList() []PropertySource {
-	if s.propertySourceList == nil {
-		s.propertySourceList = make([]PropertySource, 0)
-	}
-	return s.propertySourceList
+	list, _ := s.propertySourceList.Load().([]PropertySource)
+	return list
 }
 
-func (s *MutablePropertySources) removeIfPresent(name string) {
-	list := s.getPropertySourceList()
-	if len(list) < 1 {
-		return
+/**
+串行修改配置来源列表，modify 基于当前快照返回新的列表（不能修改传入的列表），成功之后整体替换并通知监听器
+*/
+func (s *MutablePropertySources) update(modify func(list []PropertySource) ([]PropertySource, error)) error {
+	s.lock.Lock()
+	list, err := modify(s.getPropertySourceList())
+	if err != nil {
+		s.lock.Unlock()
+		return err
 	}
-	if len(list) == 1 {
-		if list[0].GetName() == name {
-			s.propertySourceList = make([]PropertySource, 0)
-		}
-	} else {
-		newList := make([]PropertySource, 0)
-		for _, item := range list {
-			if item.GetName() != name {
-				newList = append(newList, item)
-			}
+	s.propertySourceList.Store(list)
+	s.lock.Unlock()
+
+	// 在锁外通知，监听器中可以读取甚至修改配置来源
+	s.onPropertySourceChanged()
+	return nil
+}
+
+/**
+返回删除了指定名称之后的新列表
+*/
+func removeIfPresent(list []PropertySource, name string) []PropertySource {
+	newList := make([]PropertySource, 0, len(list)+1)
+	for _, item := range list {
+		if item.GetName() != name {
+			newList = append(newList, item)
 		}
-		s.propertySourceList = newList
 	}
+	return newList
 }
 
-func (s *MutablePropertySources) assertPresentAndGetIndex(name string) (index int, err error) {
-	list := s.getPropertySourceList()
+/**
+返回在 index 处插入之后的新列表
+*/
+func insertAt(list []PropertySource, index int, propertySource PropertySource) []PropertySource {
+	newList := make([]PropertySource, 0, len(list)+1)
+	newList = append(newList, list[:index]...)
+	newList = append(newList, propertySource)
+	return append(newList, list[index:]...)
+}
+
+func assertPresentAndGetIndex(list []PropertySource, name string) (index int, err error) {
 	for index, item := range list {
 		if item.GetName() == name {
 			return index, nil
@@ -5,7 +5,9 @@ import (
 	"github.com/xkgo/sparrow/logger"
 	"github.com/xkgo/sparrow/util/GoUtils"
 	"regexp"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -32,18 +34,24 @@ func (p *PropertyReaderWrapper) ReadAll() (kvs map[string]string, err error) {
 }
 
 /**
-基于轮询实现的配置来源
+基于轮询实现的配置来源，读取的是配置快照，Reload 时整体替换快照，读取方不需要加锁
 */
 type PollingPropertySource struct {
-	Name            string            // 名称
-	PropertyReader  PropertyReader    // 配置读取实现
-	PollingInterval int64             // 轮询间隔，单位：秒
-	kvs             map[string]string // 内存配置项， key->value
+	Name            string         // 名称
+	PropertyReader  PropertyReader // 配置读取实现
+	PollingInterval int64          // 轮询间隔，单位：秒
+	kvs             atomic.Value   // 内存配置项快照， map[string]string, 存入之后不会再修改
 	scheduleOnce    sync.Once
+	reloadLock      sync.Mutex   // 串行执行 Reload，保证变更按顺序发布
+	listenerLock    sync.RWMutex // 保护订阅列表
 	/**
 	配置key变更订阅列表
 	*/
 	propertyChangeListeners []*PropertyChangeListener
+	/**
+	批量变更订阅列表
+	*/
+	changeSetListeners []func(changes *ChangeSet)
 }
 
 /*
@@ -107,34 +115,38 @@ func (p *PollingPropertySource) Reload() (err error) {
 		}()
 	}()
 
-	nkvs, err := p.PropertyReader.ReadAll()
+	p.reloadLock.Lock()
+	defer p.reloadLock.Unlock()
+
+	kvs, err := p.PropertyReader.ReadAll()
 	if err != nil {
 		return
 	}
-	if nkvs == nil {
-		nkvs = make(map[string]string)
-	}
-	if p.kvs == nil {
-		p.kvs = make(map[string]string)
+	// 复制一份作为快照，避免读取实现修改返回的 map
+	nkvs := make(map[string]string, len(kvs))
+	for k, v := range kvs {
+		nkvs[k] = v
 	}
-
-	okvs := p.kvs
+	okvs := p.snapshot()
 
 	// 新的配置
-	p.kvs = nkvs
+	p.kvs.Store(nkvs)
 
 	// 比较计算哪些属性发生变更，变化了的调用变更监听器
-	if len(p.propertyChangeListeners) < 1 || okvs == nil {
-		// 首次加载
+	p.listenerLock.RLock()
+	noListener := len(p.propertyChangeListeners) < 1 && len(p.changeSetListeners) < 1
+	p.listenerLock.RUnlock()
+	if noListener {
 		return
 	}
 
+	changes := make([]*KeyChangeEvent, 0)
 	// 判断是否有更新或者删除
 	for key, ov := range okvs {
 		nv, exists := nkvs[key]
 		if exists && nv != ov {
 			// 更新了
-			p.onKeyChangeEvent(&KeyChangeEvent{
+			changes = append(changes, &KeyChangeEvent{
 				Key:        key,
 				Ov:         ov,
 				Nv:         nv,
@@ -142,7 +154,7 @@ func (p *PollingPropertySource) Reload() (err error) {
 			})
 		} else if !exists {
 			// 删除
-			p.onKeyChangeEvent(&KeyChangeEvent{
+			changes = append(changes, &KeyChangeEvent{
 				Key:        key,
 				Ov:         ov,
 				Nv:         "",
@@ -154,7 +166,7 @@ func (p *PollingPropertySource) Reload() (err error) {
 	for key, nv := range nkvs {
 		if _, exists := okvs[key]; !exists {
 			// 添加
-			p.onKeyChangeEvent(&KeyChangeEvent{
+			changes = append(changes, &KeyChangeEvent{
 				Key:        key,
 				Ov:         "",
 				Nv:         nv,
@@ -162,17 +174,47 @@ func (p *PollingPropertySource) Reload() (err error) {
 			})
 		}
 	}
+	if len(changes) < 1 {
+		return nil
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+
+	for _, change := range changes {
+		p.onKeyChangeEvent(change)
+	}
+	p.onChangeSet(&ChangeSet{Source: p.Name, Changes: changes})
 	return nil
 }
 
+/**
+批量变更处理
+*/
+func (p *PollingPropertySource) onChangeSet(changes *ChangeSet) {
+	p.listenerLock.RLock()
+	listeners := p.changeSetListeners
+	p.listenerLock.RUnlock()
+	for _, handler := range listeners {
+		GoUtils.Run(func() {
+			handler(changes)
+		}, func(r interface{}) {
+			logger.Warn("配置源["+p.Name+"]执行批量配置变更", changes, "发生panic： ", r)
+		})
+	}
+}
+
 /**
 Key 变更处理
 */
 func (p *PollingPropertySource) onKeyChangeEvent(event *KeyChangeEvent) {
 	logger.Info("["+p.Name+"]配置发生了变更：key:["+event.Key+"], ov:["+event.Ov+"], nv:["+event.Nv+"], changeType:[", event.ChangeType+"]")
 	// 执行监听器
-	if len(p.propertyChangeListeners) > 0 {
-		for _, listener := range p.propertyChangeListeners {
+	p.listenerLock.RLock()
+	listeners := p.propertyChangeListeners
+	p.listenerLock.RUnlock()
+	if len(listeners) > 0 {
+		for _, listener := range listeners {
 			keyPattern := listener.KeyPattern
 			handler := listener.Handler
 			if handler == nil {
@@ -198,29 +240,49 @@ func (p *PollingPropertySource) GetName() string {
 	return p.Name
 }
 
+/**
+当前的配置快照，只读，还没有加载过的话返回 nil
+*/
+func (p *PollingPropertySource) snapshot() map[string]string {
+	kvs, _ := p.kvs.Load().(map[string]string)
+	return kvs
+}
+
 func (p *PollingPropertySource) GetProperty(key string) (value string, exists bool) {
-	value, exists = p.kvs[key]
+	value, exists = p.snapshot()[key]
 	return
 }
 
 func (p *PollingPropertySource) GetPropertyWithDef(key string, def string) string {
-	if value, exists := p.kvs[key]; exists && len(value) > 0 {
+	if value, exists := p.snapshot()[key]; exists && len(value) > 0 {
 		return value
 	}
 	return def
 }
 
 func (p *PollingPropertySource) Each(consumer func(key string, value string) (stop bool)) {
-	for k, v := range p.kvs {
+	for k, v := range p.snapshot() {
 		if consumer(k, v) {
 			return
 		}
 	}
 }
 
+/**
+订阅列表使用 copy-on-write，分发变更时遍历的是订阅时的列表，不需要持有锁
+*/
 func (p *PollingPropertySource) Subscribe(keyPattern string, handler func(event *KeyChangeEvent)) {
-	if p.propertyChangeListeners == nil {
-		p.propertyChangeListeners = make([]*PropertyChangeListener, 0)
-	}
-	p.propertyChangeListeners = append(p.propertyChangeListeners, NewPropertyChangeListener(keyPattern, handler))
+	p.listenerLock.Lock()
+	defer p.listenerLock.Unlock()
+	listeners := make([]*PropertyChangeListener, 0, len(p.propertyChangeListeners)+1)
+	listeners = append(listeners, p.propertyChangeListeners...)
+	p.propertyChangeListeners = append(listeners, NewPropertyChangeListener(keyPattern, handler))
+}
+
+func (p *PollingPropertySource) SubscribeChangeSet(handler func(changes *ChangeSet)) {
+	p.listenerLock.Lock()
+	defer p.listenerLock.Unlock()
+	listeners := make([]func(changes *ChangeSet), 0, len(p.changeSetListeners)+1)
+	listeners = append(listeners, p.changeSetListeners...)
+	p.changeSetListeners = append(listeners, handler)
 }
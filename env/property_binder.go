@@ -0,0 +1,397 @@
+package env
+
+import (
+	"fmt"
+	"github.com/xkgo/sparrow/logger"
+	"github.com/xkgo/sparrow/util/GoUtils"
+	"github.com/xkgo/sparrow/util/ReflectUtils"
+	"github.com/xkgo/sparrow/util/StringUtils"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+/**
+一次绑定过程的上下文，收集转换失败、校验失败的配置项
+*/
+type propertyBinder struct {
+	violations []*PropertyViolation
+}
+
+/**
+监听配置变化的配置Bean
+*/
+type boundProperties struct {
+	keyPrefix string
+	beanType  reflect.Type
+	bean      reflect.Value // 配置Bean指针
+	current   *atomic.Value // 最新配置的快照（和 bean 同类型的指针），每次变更都是新的对象
+}
+
+/**
+递归绑定结构体的属性，支持：
+1. 嵌套结构体：prefix.field.xxx
+2. Slice：prefix.field[0]、prefix.field[1].host 等下标形式，或者直接配置 JSON、英文逗号分隔的列表
+3. map[string]T：prefix.field. 下的所有配置，T 为结构体的话按照第一段 key 分组
+4. 结构体指针：存在对应前缀的配置时才会创建
+sync 包中的类型（如：sync.RWMutex）不会绑定，reset 为 true 的话，没有任何配置的结构体指针、Slice、Map 会被重置为零值
+*/
+func (s *StandardEnvironment) bindStruct(keyPrefix string, t reflect.Type, v reflect.Value, reset bool, binder *propertyBinder) {
+	for i := 0; i < t.NumField(); i++ {
+		tfield := t.Field(i)
+		vfield := v.Field(i)
+		if isSyncType(tfield.Type) {
+			continue
+		}
+
+		configKey := propertyFieldKey(keyPrefix, tfield)
+
+		// 初始值
+		initVal := tfield.Tag.Get("def")
+
+		if isNestedPropertyType(tfield.Type) {
+			_, exists := s.GetProperty(configKey)
+			if tfield.Type.Kind() == reflect.Struct && !exists && (len(initVal) < 1 || len(propertyKeysWithPrefix(s.GetPropertySources(), configKey+".")) > 0) {
+				// 嵌套结构体，直接在原有的结构体上绑定
+				s.bindStruct(configKey+".", tfield.Type, vfield, reset, binder)
+				continue
+			}
+			s.applyNestedPropertyValue(t, tfield, vfield, configKey, initVal, reset, binder)
+			continue
+		}
+
+		// 获取配置的值
+		value, exists := s.GetProperty(configKey)
+		if !exists {
+			value = s.ResolvePlaceholders(initVal)
+		}
+		// 反射进行配置回写
+		if err := s.applyBeanPropertyValue(t, tfield, vfield, initVal, value, PropertyUpdate); err != nil {
+			binder.violations = append(binder.violations, s.newPropertyViolation(configKey, value, err.Error()))
+		}
+	}
+}
+
+/**
+配置变更之后刷新配置Bean：基于当前快照创建新的对象，重新绑定所有属性并校验，校验通过之后通过原子替换发布新的快照，否则保持原值，
+快照一旦发布就不会再被修改，BindListen 返回的 Properties 读取的就是最新的快照；
+之后将新的配置整体写入配置Bean，配置Bean实现了 sync.Locker（如：嵌入 sync.RWMutex）的话会在加锁之后写入，读取方加读锁即可读到一致的配置，
+没有实现 sync.Locker 的话直接写入，并发读取可能读到部分更新的配置；配置Bean实现了 PropertiesChangedListener 的话，更新之后会回调
+*/
+func (s *StandardEnvironment) refreshBoundProperties(bound *boundProperties, changeSet *ChangeSet) {
+	changes := changeSet.WithPrefix(bound.keyPrefix)
+	if len(changes) < 1 {
+		return
+	}
+
+	t := bound.beanType
+	old := reflect.ValueOf(bound.current.Load())
+	candidate := reflect.New(t)
+	copyProperties(candidate.Elem(), old.Elem())
+
+	binder := &propertyBinder{}
+	s.bindStruct(bound.keyPrefix, t, candidate.Elem(), true, binder)
+	binder.violations = append(binder.violations, s.validateStruct(bound.keyPrefix, t, candidate.Elem())...)
+	if len(binder.violations) > 0 {
+		logger.Error("配置变更", changeSet, "校验失败，保持原值：", &PropertyValidationError{Target: t.String(), Violations: binder.violations})
+		return
+	}
+	if reflect.DeepEqual(old.Interface(), candidate.Interface()) {
+		return
+	}
+
+	// 发布新的快照
+	bound.current.Store(candidate.Interface())
+	if locker, ok := bound.bean.Interface().(sync.Locker); ok {
+		// 整体更新
+		locker.Lock()
+		copyProperties(bound.bean.Elem(), candidate.Elem())
+		locker.Unlock()
+	} else {
+		copyProperties(bound.bean.Elem(), candidate.Elem())
+	}
+	logger.Info("配置Bean["+t.Name()+"]已更新，变更：", changeSet)
+
+	if listener, ok := bound.bean.Interface().(PropertiesChangedListener); ok {
+		GoUtils.Run(func() {
+			listener.OnPropertiesChanged(old.Interface(), candidate.Interface(), changes)
+		}, func(r interface{}) {
+			logger.Error("配置Bean["+t.Name()+"]执行 OnPropertiesChanged 发生panic: ", r)
+		})
+	}
+}
+
+/**
+创建配置Bean的快照
+*/
+func newPropertiesSnapshot(bean reflect.Value) *atomic.Value {
+	snapshot := reflect.New(bean.Type().Elem())
+	copyProperties(snapshot.Elem(), bean.Elem())
+	current := &atomic.Value{}
+	current.Store(snapshot.Interface())
+	return current
+}
+
+/**
+复制结构体的属性（包括私有属性），sync 包中的类型除外
+*/
+func copyProperties(dst, src reflect.Value) {
+	for i := 0; i < dst.NumField(); i++ {
+		if isSyncType(dst.Type().Field(i).Type) {
+			continue
+		}
+		settableValue(dst.Field(i)).Set(settableValue(src.Field(i)))
+	}
+}
+
+/**
+是否是 sync 包中的类型或者其指针，如：sync.Mutex、*sync.RWMutex
+*/
+func isSyncType(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.PkgPath() == "sync"
+}
+
+/**
+属性对应的配置 key，默认是首字母小写，可以通过 ck 或者 sk 标签指定
+*/
+func propertyFieldKey(keyPrefix string, tfield reflect.StructField) string {
+	subKey := tfield.Tag.Get("ck")
+	if len(subKey) < 1 {
+		subKey = tfield.Tag.Get("sk")
+	}
+	if len(subKey) > 0 {
+		return keyPrefix + subKey
+	}
+	return keyPrefix + StringUtils.FirstLetterLower(tfield.Name)
+}
+
+/**
+是否需要按照嵌套 key 绑定：结构体、结构体指针、Slice（[]byte 除外）、key 为 string 的 Map
+*/
+func isNestedPropertyType(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Struct:
+		return true
+	case reflect.Ptr:
+		return t.Elem().Kind() == reflect.Struct
+	case reflect.Slice:
+		return t.Elem().Kind() != reflect.Uint8
+	case reflect.Map:
+		return t.Key().Kind() == reflect.String
+	}
+	return false
+}
+
+/**
+重新构造嵌套属性的值并设置，没有任何配置的话使用 def 初始值，reset 为 true 并且也没有初始值的话设置为零值
+*/
+func (s *StandardEnvironment) applyNestedPropertyValue(beanType reflect.Type, tfield reflect.StructField, vfield reflect.Value, configKey, initVal string, reset bool, binder *propertyBinder) {
+	before := len(binder.violations)
+	value, exists := s.resolvePropertyValue(configKey, tfield.Type, binder)
+	if len(binder.violations) > before {
+		return
+	}
+	if !exists {
+		if len(initVal) > 0 {
+			initVal = s.ResolvePlaceholders(initVal)
+			if err := s.applyBeanPropertyValue(beanType, tfield, vfield, initVal, initVal, PropertyUpdate); err != nil {
+				binder.violations = append(binder.violations, s.newPropertyViolation(configKey, initVal, err.Error()))
+			}
+			return
+		}
+		if !reset {
+			return
+		}
+		value = reflect.Zero(tfield.Type)
+	}
+	settableValue(vfield).Set(value)
+}
+
+/**
+按照 key 构造 t 类型的值，key 本身存在配置的话直接转换，否则按照嵌套 key 构造，exists 表示是否存在相关的配置，转换失败的配置项记录到 binder 中
+*/
+func (s *StandardEnvironment) resolvePropertyValue(key string, t reflect.Type, binder *propertyBinder) (value reflect.Value, exists bool) {
+	if raw, ok := s.GetProperty(key); ok {
+		value, err := convertPropertyValue(raw, t)
+		if err != nil {
+			binder.violations = append(binder.violations, s.newPropertyViolation(key, raw, "无法转换成 "+t.String()+": "+err.Error()))
+			return value, false
+		}
+		return value, true
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		if t.Elem().Kind() != reflect.Struct {
+			return
+		}
+		elem, exists := s.resolvePropertyValue(key, t.Elem(), binder)
+		if !exists {
+			return value, false
+		}
+		value = reflect.New(t.Elem())
+		value.Elem().Set(elem)
+		return value, true
+	case reflect.Struct:
+		if len(propertyKeysWithPrefix(s.GetPropertySources(), key+".")) < 1 {
+			return
+		}
+		value = reflect.New(t).Elem()
+		s.bindStruct(key+".", t, value, false, binder)
+		return value, true
+	case reflect.Slice:
+		return s.resolveSliceValue(key, t, binder)
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return
+		}
+		return s.resolveMapValue(key, t, binder)
+	}
+	return
+}
+
+/**
+按照下标构造 Slice，如：servers[0].host、servers[1].host，缺失的下标为零值
+*/
+func (s *StandardEnvironment) resolveSliceValue(key string, t reflect.Type, binder *propertyBinder) (value reflect.Value, exists bool) {
+	prefix := key + "["
+	size := 0
+	for _, k := range propertyKeysWithPrefix(s.GetPropertySources(), prefix) {
+		end := strings.Index(k, "]")
+		if end < 0 {
+			continue
+		}
+		index, perr := strconv.Atoi(k[len(prefix):end])
+		if perr != nil || index < 0 {
+			continue
+		}
+		if index+1 > size {
+			size = index + 1
+		}
+	}
+	if size < 1 {
+		return
+	}
+
+	value = reflect.MakeSlice(t, size, size)
+	for i := 0; i < size; i++ {
+		if elem, ok := s.resolvePropertyValue(key+"["+strconv.Itoa(i)+"]", t.Elem(), binder); ok {
+			value.Index(i).Set(elem)
+		}
+	}
+	return value, true
+}
+
+/**
+构造 map[string]T，T 为嵌套类型的话按照第一段 key 分组，否则 prefix. 之后的整个 key 作为 map 的 key
+*/
+func (s *StandardEnvironment) resolveMapValue(key string, t reflect.Type, binder *propertyBinder) (value reflect.Value, exists bool) {
+	prefix := key + "."
+	keys := propertyKeysWithPrefix(s.GetPropertySources(), prefix)
+	if len(keys) < 1 {
+		return
+	}
+
+	nested := isNestedPropertyType(t.Elem())
+	resolved := make(map[string]bool)
+	value = reflect.MakeMap(t)
+	for _, k := range keys {
+		name := k[len(prefix):]
+		if nested {
+			if end := strings.IndexAny(name, ".["); end >= 0 {
+				name = name[:end]
+			}
+		}
+		if resolved[name] {
+			continue
+		}
+		resolved[name] = true
+		if elem, ok := s.resolvePropertyValue(prefix+name, t.Elem(), binder); ok {
+			value.SetMapIndex(reflect.ValueOf(name).Convert(t.Key()), elem)
+		}
+	}
+	return value, true
+}
+
+/**
+转换配置值，Slice 类型在 JSON 转换失败的时候按照英文逗号分隔
+*/
+func convertPropertyValue(raw string, t reflect.Type) (value reflect.Value, err error) {
+	value, err = ReflectUtils.ConvertTo(raw, t)
+	if err == nil || t.Kind() != reflect.Slice || strings.HasPrefix(strings.TrimSpace(raw), "[") {
+		return
+	}
+	items := strings.Split(raw, ",")
+	value = reflect.MakeSlice(t, 0, len(items))
+	for _, item := range items {
+		elem, err := ReflectUtils.ConvertTo(strings.TrimSpace(item), t.Elem())
+		if err != nil {
+			return value, err
+		}
+		value = reflect.Append(value, elem)
+	}
+	return value, nil
+}
+
+/**
+获取所有配置来源中以 prefix 开头的 key，已经排序并去重
+*/
+func propertyKeysWithPrefix(sources PropertySources, prefix string) []string {
+	keySet := make(map[string]bool)
+	sources.Each(func(index int, source PropertySource) (stop bool) {
+		source.Each(func(key, value string) (stop bool) {
+			if strings.HasPrefix(key, prefix) {
+				keySet[key] = true
+			}
+			return false
+		})
+		return false
+	})
+	keys := make([]string, 0, len(keySet))
+	for key := range keySet {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+/**
+获取可以设置的 Value，私有属性通过 unsafe 设置
+*/
+func settableValue(v reflect.Value) reflect.Value {
+	if v.CanSet() {
+		return v
+	}
+	return reflect.NewAt(v.Type(), unsafe.Pointer(v.UnsafeAddr())).Elem()
+}
+
+/**
+展开配置文件中的列表，如：servers: [{host: a}] 展开成 servers[0].host=a
+*/
+func flattenPropertyValue(props map[string]string, key string, value interface{}) {
+	switch val := value.(type) {
+	case []interface{}:
+		for i, item := range val {
+			flattenPropertyValue(props, key+"["+strconv.Itoa(i)+"]", item)
+		}
+	case map[string]interface{}:
+		for k, item := range val {
+			flattenPropertyValue(props, key+"."+k, item)
+		}
+	case map[interface{}]interface{}:
+		for k, item := range val {
+			flattenPropertyValue(props, key+"."+fmt.Sprint(k), item)
+		}
+	case nil:
+		props[key] = ""
+	default:
+		props[key] = fmt.Sprint(val)
+	}
+}
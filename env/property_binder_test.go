@@ -0,0 +1,105 @@
+package env
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type ServerNode struct {
+	Host   string `ck:"host"`
+	Port   int    `ck:"port" def:"80"`
+	Weight int    `ck:"weight"`
+}
+
+type PoolConfig struct {
+	MaxIdle int `ck:"max-idle" def:"8"`
+	MaxOpen int `ck:"max-open"`
+}
+
+type ClusterConfig struct {
+	Name    string                 `ck:"name"`
+	Pool    PoolConfig             `ck:"pool"`
+	Backup  *PoolConfig            `ck:"backup"`
+	Missing *PoolConfig            `ck:"missing"`
+	Servers []ServerNode           `ck:"servers"`
+	Tags    []string               `ck:"tags"`
+	Zones   []string               `ck:"zones"`
+	Labels  map[string]string      `ck:"labels"`
+	Shards  map[string]*ServerNode `ck:"shards"`
+	Headers []string               `ck:"headers" def:"[\"trace-id\"]"`
+}
+
+func TestStandardEnvironment_BindNestedProperties(t *testing.T) {
+	properties := map[string]string{
+		"cluster.name":              "order",
+		"cluster.pool.max-open":     "32",
+		"cluster.backup.max-idle":   "2",
+		"cluster.servers[0].host":   "10.0.0.1",
+		"cluster.servers[0].port":   "8080",
+		"cluster.servers[1].host":   "10.0.0.2",
+		"cluster.tags":              "a, b,c",
+		"cluster.zones[0]":          "wuxi",
+		"cluster.zones[1]":          "shanghai",
+		"cluster.labels.app":        "order",
+		"cluster.labels.team.owner": "trade",
+		"cluster.shards.s1.host":    "10.0.1.1",
+		"cluster.shards.s2.host":    "10.0.1.2",
+		"cluster.shards.s2.weight":  "3",
+	}
+	reader := NewPropertyReader(func() (kvs map[string]string, err error) {
+		kvs = make(map[string]string)
+		for k, v := range properties {
+			kvs[k] = v
+		}
+		return kvs, nil
+	})
+	source, _ := NewPollingPropertySource("cluster", 0, reader)
+
+	env := New(AdditionalPropertySources(NewMutablePropertySources(source)))
+	snapshot, err := BindListen[ClusterConfig](env, "cluster.")
+	assert.Nil(t, err)
+	cfg := snapshot.Get()
+
+	assert.Equal(t, "order", cfg.Name)
+	assert.Equal(t, PoolConfig{MaxIdle: 8, MaxOpen: 32}, cfg.Pool)
+	assert.Equal(t, &PoolConfig{MaxIdle: 2}, cfg.Backup)
+	assert.Nil(t, cfg.Missing)
+	assert.Equal(t, []ServerNode{{Host: "10.0.0.1", Port: 8080}, {Host: "10.0.0.2", Port: 80}}, cfg.Servers)
+	assert.Equal(t, []string{"a", "b", "c"}, cfg.Tags)
+	assert.Equal(t, []string{"wuxi", "shanghai"}, cfg.Zones)
+	assert.Equal(t, map[string]string{"app": "order", "team.owner": "trade"}, cfg.Labels)
+	assert.Equal(t, 2, len(cfg.Shards))
+	assert.Equal(t, 3, cfg.Shards["s2"].Weight)
+	assert.Equal(t, []string{"trace-id"}, cfg.Headers)
+
+	// 嵌套 key 变更
+	properties["cluster.pool.max-open"] = "64"
+	properties["cluster.servers[2].host"] = "10.0.0.3"
+	delete(properties, "cluster.backup.max-idle")
+	properties["cluster.missing.max-open"] = "4"
+	_ = source.Reload()
+
+	cfg = snapshot.Get()
+	assert.Equal(t, 64, cfg.Pool.MaxOpen)
+	assert.Equal(t, 3, len(cfg.Servers))
+	assert.Equal(t, "10.0.0.3", cfg.Servers[2].Host)
+	assert.Nil(t, cfg.Backup)
+	assert.Equal(t, &PoolConfig{MaxIdle: 8, MaxOpen: 4}, cfg.Missing)
+}
+
+func TestReadLocalFileAsPropertySource_FlattenList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "application.yml")
+	content := "cluster:\n  servers:\n    - host: 10.0.0.1\n      port: 8080\n    - host: 10.0.0.2\n  zones: [wuxi, shanghai]\n"
+	assert.Nil(t, os.WriteFile(path, []byte(content), 0644))
+
+	source, err := ReadLocalFileAsPropertySource("yml", path)
+	assert.Nil(t, err)
+
+	env := New(AdditionalPropertySources(NewMutablePropertySources(source)))
+	cfg, err := Bind[ClusterConfig](env, "cluster.")
+	assert.Nil(t, err)
+	assert.Equal(t, []ServerNode{{Host: "10.0.0.1", Port: 8080}, {Host: "10.0.0.2", Port: 80}}, cfg.Servers)
+	assert.Equal(t, []string{"wuxi", "shanghai"}, cfg.Zones)
+}
@@ -1,5 +1,7 @@
 package env
 
+import "time"
+
 /*
 Interface for resolving properties against any underlying source.
 */
@@ -38,3 +40,37 @@ type PropertyResolver interface {
 	*/
 	ResolveRequiredPlaceholders(text string) string
 }
+
+/**
+支持类型转换的配置解析器，获取指定类型的配置项（已处理占位符）：
+Get{Type} 配置项不存在的话 exists 为 false，转换失败返回 *PropertyConvertError（包含 key、原始值、配置来源）；
+Get{Type}WithDef 配置项不存在或者转换失败时返回默认值；GetRequired{Type} 配置项不存在或者转换失败时直接 panic
+*/
+type TypedPropertyResolver interface {
+	PropertyResolver
+
+	GetInt(key string) (value int, exists bool, err error)
+	GetIntWithDef(key string, def int) int
+	GetRequiredInt(key string) int
+	GetInt64(key string) (value int64, exists bool, err error)
+	GetInt64WithDef(key string, def int64) int64
+	GetRequiredInt64(key string) int64
+	GetBool(key string) (value bool, exists bool, err error)
+	GetBoolWithDef(key string, def bool) bool
+	GetRequiredBool(key string) bool
+	GetFloat64(key string) (value float64, exists bool, err error)
+	GetFloat64WithDef(key string, def float64) float64
+	GetRequiredFloat64(key string) float64
+	GetDuration(key string) (value time.Duration, exists bool, err error)
+	GetDurationWithDef(key string, def time.Duration) time.Duration
+	GetRequiredDuration(key string) time.Duration
+	GetStringSlice(key string) (value []string, exists bool, err error)
+	GetStringSliceWithDef(key string, def []string) []string
+	GetRequiredStringSlice(key string) []string
+	GetStringMap(key string) (value map[string]string, exists bool, err error)
+	GetStringMapWithDef(key string, def map[string]string) map[string]string
+	GetRequiredStringMap(key string) map[string]string
+	GetTime(key string) (value time.Time, exists bool, err error)
+	GetTimeWithDef(key string, def time.Time) time.Time
+	GetRequiredTime(key string) time.Time
+}
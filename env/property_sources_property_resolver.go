@@ -2,6 +2,7 @@ package env
 
 import (
 	"github.com/xkgo/sparrow/logger"
+	"sync"
 )
 
 /**
@@ -12,6 +13,7 @@ type PropertySourcesPropertyResolver struct {
 	ignoreUnresolvableNestedPlaceholders bool                       // 是否忽略无法处理的占位符，如果忽略则不处理，不忽略的话，那么遇到不能解析的占位符直接 panic
 	nonStrictHelper                      *PropertyPlaceholderHelper // 当遇到未定义的配置项时，不进行替换，也不会抛出异常
 	strictHelper                         *PropertyPlaceholderHelper // 当遇到未定义的配置项时，直接 panic
+	helperOnce                           sync.Once                  // 并发读取时只创建一次占位符处理器
 }
 
 /**
@@ -45,13 +47,22 @@ func (p *PropertySourcesPropertyResolver) ContainsProperty(key string) bool {
 @param resolveNestedPlaceholders 是否需要处理占位符
 */
 func (p *PropertySourcesPropertyResolver) doGetProperty(key string, resolveNestedPlaceholders bool) (value string, exists bool) {
+	value, _, exists = p.doGetPropertyWithSource(key, resolveNestedPlaceholders)
+	return
+}
+
+/**
+获取配置项以及生效的配置来源名称
+*/
+func (p *PropertySourcesPropertyResolver) doGetPropertyWithSource(key string, resolveNestedPlaceholders bool) (value string, sourceName string, exists bool) {
 	if nil == p.propertySources {
-		return "", false
+		return "", "", false
 	}
 	p.propertySources.Each(func(index int, source PropertySource) (stop bool) {
 		if val, ok := source.GetProperty(key); ok {
 			exists = true
 			value = val
+			sourceName = source.GetName()
 
 			// 找到了key，加下日志
 			if logger.IsDebugEnabled() {
@@ -88,19 +99,22 @@ func (p *PropertySourcesPropertyResolver) GetRequiredProperty(key string) string
 }
 
 func (p *PropertySourcesPropertyResolver) ResolvePlaceholders(text string) string {
-	if p.nonStrictHelper == nil {
-		p.nonStrictHelper = p.createPlaceholderHelper(true)
-	}
+	p.initPlaceholderHelpers()
 	return p.doResolvePlaceholders(text, p.nonStrictHelper)
 }
 
 func (p *PropertySourcesPropertyResolver) ResolveRequiredPlaceholders(text string) string {
-	if p.strictHelper == nil {
-		p.strictHelper = p.createPlaceholderHelper(false)
-	}
+	p.initPlaceholderHelpers()
 	return p.doResolvePlaceholders(text, p.strictHelper)
 }
 
+func (p *PropertySourcesPropertyResolver) initPlaceholderHelpers() {
+	p.helperOnce.Do(func() {
+		p.nonStrictHelper = p.createPlaceholderHelper(true)
+		p.strictHelper = p.createPlaceholderHelper(false)
+	})
+}
+
 /**
 处理占位符，将占位符为 ${...} 替换掉
 */
@@ -0,0 +1,251 @@
+package env
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/**
+配置项校验失败信息
+*/
+type PropertyViolation struct {
+	Key     string // 配置 key
+	Value   string // 配置值
+	Source  string // 配置来源名称，没有配置（使用默认值）的话为空
+	Message string // 失败原因
+}
+
+func (v *PropertyViolation) String() string {
+	source := v.Source
+	if len(source) < 1 {
+		source = "未配置"
+	}
+	return v.Key + "=[" + v.Value + "](来源: " + source + "): " + v.Message
+}
+
+/**
+配置Bean校验失败，包含所有校验失败的配置项
+*/
+type PropertyValidationError struct {
+	Target     string               // 配置Bean类型
+	Violations []*PropertyViolation // 校验失败的配置项
+}
+
+func (e *PropertyValidationError) Error() string {
+	lines := make([]string, 0, len(e.Violations))
+	for _, violation := range e.Violations {
+		lines = append(lines, violation.String())
+	}
+	return "配置Bean[" + e.Target + "]校验失败：\n" + strings.Join(lines, "\n")
+}
+
+/**
+创建校验失败信息，并找到配置来源
+*/
+func (s *StandardEnvironment) newPropertyViolation(key, value, message string) *PropertyViolation {
+	return &PropertyViolation{Key: key, Value: value, Source: s.propertySourceName(key), Message: message}
+}
+
+/**
+获取配置项生效的配置来源名称，没有配置的话返回空字符串
+*/
+func (s *StandardEnvironment) propertySourceName(key string) (name string) {
+	s.GetPropertySources().Each(func(index int, source PropertySource) (stop bool) {
+		if _, exists := source.GetProperty(key); exists {
+			name = source.GetName()
+			return true
+		}
+		return false
+	})
+	return
+}
+
+/**
+按照 validate 标签校验结构体，嵌套的结构体、结构体指针、Slice、Map 的元素会递归校验，支持的规则（英文逗号分隔）：
+1. required：不能为零值，非 required 的属性为零值时不检查其他规则
+2. min=n、max=n：数值的大小，字符串、Slice、Map 的长度，time.Duration 可以使用 1s 这样的格式
+3. oneof=a b c：必须是其中之一，空格分隔
+4. url：合法的 URL，必须包含 scheme 和 host
+5. duration：合法的 time.Duration 格式，如：30s
+6. pattern=regex：匹配正则表达式，必须是最后一个规则，之后的内容（包括英文逗号）都作为正则表达式
+如：
+
+	Port int `ck:"port" validate:"required,min=1,max=65535"`
+*/
+func (s *StandardEnvironment) validateStruct(keyPrefix string, t reflect.Type, v reflect.Value) (violations []*PropertyViolation) {
+	for i := 0; i < t.NumField(); i++ {
+		tfield := t.Field(i)
+		if isSyncType(tfield.Type) {
+			continue
+		}
+		vfield := settableValue(v.Field(i))
+		configKey := propertyFieldKey(keyPrefix, tfield)
+
+		if tag, ok := tfield.Tag.Lookup("validate"); ok {
+			for _, message := range validatePropertyValue(vfield, tag) {
+				violations = append(violations, s.newPropertyViolation(configKey, formatPropertyValue(vfield), message))
+			}
+		}
+		violations = append(violations, s.validateNestedValue(configKey, vfield)...)
+	}
+	return
+}
+
+func (s *StandardEnvironment) validateNestedValue(key string, v reflect.Value) (violations []*PropertyViolation) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() && v.Elem().Kind() == reflect.Struct {
+			return s.validateStruct(key+".", v.Type().Elem(), v.Elem())
+		}
+	case reflect.Struct:
+		if v.CanAddr() {
+			return s.validateStruct(key+".", v.Type(), v)
+		}
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(v)
+		return s.validateStruct(key+".", v.Type(), copied)
+	case reflect.Slice:
+		if !isNestedPropertyType(v.Type()) {
+			return
+		}
+		for i := 0; i < v.Len(); i++ {
+			violations = append(violations, s.validateNestedValue(key+"["+strconv.Itoa(i)+"]", v.Index(i))...)
+		}
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return
+		}
+		for _, mapKey := range v.MapKeys() {
+			violations = append(violations, s.validateNestedValue(key+"."+mapKey.String(), v.MapIndex(mapKey))...)
+		}
+	}
+	return
+}
+
+/**
+按照规则校验属性值，返回所有失败原因
+*/
+func validatePropertyValue(v reflect.Value, tag string) (messages []string) {
+	rules := strings.Split(tag, ",")
+	for i, rule := range rules {
+		if strings.HasPrefix(strings.TrimSpace(rule), "pattern=") {
+			// 正则表达式中可能包含英文逗号
+			rules = append(rules[:i], strings.Join(rules[i:], ","))
+			break
+		}
+	}
+
+	required := false
+	for _, rule := range rules {
+		if strings.TrimSpace(rule) == "required" {
+			required = true
+		}
+	}
+	if v.IsZero() {
+		if required {
+			messages = append(messages, "不能为空")
+		}
+		return
+	}
+
+	for _, rule := range rules {
+		name, arg := strings.TrimSpace(rule), ""
+		if idx := strings.Index(name, "="); idx >= 0 {
+			name, arg = name[:idx], name[idx+1:]
+		}
+		var message string
+		switch name {
+		case "", "required":
+			continue
+		case "min", "max":
+			message = validatePropertyRange(v, name, arg)
+		case "oneof":
+			if !containsPropertyValue(strings.Fields(arg), formatPropertyValue(v)) {
+				message = "必须是 [" + arg + "] 其中之一"
+			}
+		case "url":
+			if u, err := url.Parse(formatPropertyValue(v)); err != nil || len(u.Scheme) < 1 || len(u.Host) < 1 {
+				message = "不是合法的 URL"
+			}
+		case "duration":
+			if v.Type() != reflect.TypeOf(time.Duration(0)) {
+				if _, err := time.ParseDuration(formatPropertyValue(v)); err != nil {
+					message = "不是合法的时间间隔，如：30s、1m"
+				}
+			}
+		case "pattern":
+			regex, err := regexp.Compile(arg)
+			if err != nil {
+				message = "非法的校验正则表达式[" + arg + "]: " + err.Error()
+			} else if !regex.MatchString(formatPropertyValue(v)) {
+				message = "不匹配 " + arg
+			}
+		default:
+			message = "未知的校验规则: " + rule
+		}
+		if len(message) > 0 {
+			messages = append(messages, message)
+		}
+	}
+	return
+}
+
+/**
+校验 min、max，数值比较大小，字符串、Slice、Map 比较长度
+*/
+func validatePropertyRange(v reflect.Value, name, arg string) string {
+	var actual, limit float64
+	var err error
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map:
+		actual = float64(v.Len())
+		limit, err = strconv.ParseFloat(arg, 64)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		actual = float64(v.Int())
+		limit, err = strconv.ParseFloat(arg, 64)
+		if err != nil && v.Type() == reflect.TypeOf(time.Duration(0)) {
+			var duration time.Duration
+			duration, err = time.ParseDuration(arg)
+			limit = float64(duration)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		actual = float64(v.Uint())
+		limit, err = strconv.ParseFloat(arg, 64)
+	case reflect.Float32, reflect.Float64:
+		actual = v.Float()
+		limit, err = strconv.ParseFloat(arg, 64)
+	default:
+		return name + " 不支持类型 " + v.Type().String()
+	}
+	if err != nil {
+		return "非法的校验规则 " + name + "=" + arg
+	}
+	if name == "min" && actual < limit {
+		return "不能小于 " + arg
+	}
+	if name == "max" && actual > limit {
+		return "不能大于 " + arg
+	}
+	return ""
+}
+
+func containsPropertyValue(values []string, value string) bool {
+	for _, item := range values {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+func formatPropertyValue(v reflect.Value) string {
+	if v.Kind() == reflect.String {
+		return v.String()
+	}
+	return fmt.Sprint(v.Interface())
+}
@@ -0,0 +1,98 @@
+package env
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type UpstreamConfig struct {
+	Url     string        `ck:"url" validate:"required,url"`
+	Timeout time.Duration `ck:"timeout" def:"3s" validate:"min=1ms,max=1m"`
+}
+
+type GatewayConfig struct {
+	Port      int              `ck:"port" validate:"required,min=1,max=65535"`
+	Mode      string           `ck:"mode" def:"release" validate:"oneof=debug release test"`
+	Name      string           `ck:"name" validate:"pattern=^[a-z]{2,8}(-[a-z0-9]+)?$"`
+	Interval  string           `ck:"interval" validate:"duration"`
+	Upstreams []UpstreamConfig `ck:"upstreams"`
+}
+
+func newGatewayEnvironment(properties map[string]string) (*StandardEnvironment, *PollingPropertySource) {
+	reader := NewPropertyReader(func() (kvs map[string]string, err error) {
+		kvs = make(map[string]string)
+		for k, v := range properties {
+			kvs[k] = v
+		}
+		return kvs, nil
+	})
+	source, _ := NewPollingPropertySource("gateway", 0, reader)
+	return New(AdditionalPropertySources(NewMutablePropertySources(source))), source
+}
+
+func TestStandardEnvironment_ValidateProperties(t *testing.T) {
+	env, _ := newGatewayEnvironment(map[string]string{
+		"gateway.port":                 "70000",
+		"gateway.mode":                 "prod",
+		"gateway.name":                 "Gateway",
+		"gateway.interval":             "10",
+		"gateway.upstreams[0].url":     "order-service",
+		"gateway.upstreams[1].url":     "http://10.0.0.1:8080",
+		"gateway.upstreams[1].timeout": "5m",
+	})
+
+	_, err := Bind[GatewayConfig](env, "gateway.")
+	assert.NotNil(t, err)
+
+	var validationErr *PropertyValidationError
+	assert.True(t, errors.As(err, &validationErr))
+	keys := make([]string, 0)
+	for _, violation := range validationErr.Violations {
+		keys = append(keys, violation.Key)
+		assert.Equal(t, "gateway", violation.Source)
+	}
+	assert.Equal(t, []string{"gateway.port", "gateway.mode", "gateway.name", "gateway.interval",
+		"gateway.upstreams[0].url", "gateway.upstreams[1].timeout"}, keys)
+	assert.Contains(t, err.Error(), "gateway.port=[70000](来源: gateway): 不能大于 65535")
+
+	// 转换失败同样会导致绑定失败
+	env, _ = newGatewayEnvironment(map[string]string{"gateway.port": "abc"})
+	_, err = Bind[GatewayConfig](env, "gateway.")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "gateway.port=[abc](来源: gateway)")
+
+	// 缺少必填配置
+	env, _ = newGatewayEnvironment(map[string]string{})
+	_, err = Bind[GatewayConfig](env, "gateway.")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "gateway.port=[0](来源: 未配置): 不能为空")
+}
+
+func TestStandardEnvironment_ValidatePropertiesOnChange(t *testing.T) {
+	properties := map[string]string{
+		"gateway.port":             "8080",
+		"gateway.name":             "gw",
+		"gateway.upstreams[0].url": "http://10.0.0.1",
+	}
+	env, source := newGatewayEnvironment(properties)
+	snapshot, err := BindListen[GatewayConfig](env, "gateway.")
+	assert.Nil(t, err)
+	assert.Equal(t, "release", snapshot.Get().Mode)
+	assert.Equal(t, 3*time.Second, snapshot.Get().Upstreams[0].Timeout)
+
+	// 非法的变更被拒绝，保持原值
+	properties["gateway.port"] = "0"
+	properties["gateway.upstreams[1].url"] = "not a url"
+	_ = source.Reload()
+	assert.Equal(t, 8080, snapshot.Get().Port)
+	assert.Len(t, snapshot.Get().Upstreams, 1)
+
+	// 合法的变更正常生效
+	properties["gateway.port"] = "9090"
+	properties["gateway.upstreams[1].url"] = "http://10.0.0.2"
+	_ = source.Reload()
+	assert.Equal(t, 9090, snapshot.Get().Port)
+	assert.Len(t, snapshot.Get().Upstreams, 2)
+}
@@ -14,6 +14,8 @@ import (
 	"path/filepath"
 	"reflect"
 	"regexp"
+	"sync"
+	"sync/atomic"
 )
 
 const (
@@ -77,17 +79,39 @@ type StandardEnvironment struct {
 	/**
 	配置解析器，读取配置、处理占位符
 	*/
-	propertyResolver PropertyResolver
+	propertyResolver TypedPropertyResolver
+	resolverOnce     sync.Once
+	sourcesOnce      sync.Once
+
+	/**
+	保护 activeProfiles、订阅列表以及配置Bean，订阅列表使用 copy-on-write，分发变更时不持有锁
+	*/
+	lock sync.RWMutex
+
+	/**
+	串行处理配置变更，多个配置来源同时变更时，配置Bean按顺序刷新
+	*/
+	changeLock sync.Mutex
 
 	/**
 	配置key变更订阅列表
 	*/
 	propertyChangeListeners []*PropertyChangeListener
 
+	/**
+	批量变更订阅列表
+	*/
+	changeSetListeners []func(changes *ChangeSet)
+
 	/**
 	Beans
 	*/
 	bindBeans map[reflect.Type]interface{}
+
+	/**
+	监听配置变化的配置Bean
+	*/
+	boundProperties []*boundProperties
 }
 
 func (s *StandardEnvironment) IsDev() bool {
@@ -133,7 +157,10 @@ func New(options ...Option) *StandardEnvironment {
 
 	env.propertySources = NewMutablePropertySources()
 	var logProp *logger.Properties = nil
+	var logLock sync.Mutex
 	env.propertySources.Subscribe(func(self *MutablePropertySources) {
+		logLock.Lock()
+		defer logLock.Unlock()
 		prop := &logger.Properties{}
 		_, _ = env.doBindProperties("logger.", prop, false)
 		if env.deployInfo != nil && env.deployInfo.Env == deploy.Dev {
@@ -291,7 +318,7 @@ func New(options ...Option) *StandardEnvironment {
 
 	// 将 additionalPropertySources 添加到 propertySources 之后
 	additionalPropertySources := env.options.additionalPropertySources
-	if nil != additionalPropertySources && len(additionalPropertySources.propertySourceList) > 0 {
+	if nil != additionalPropertySources && additionalPropertySources.Size() > 0 {
 		additionalPropertySources.Each(func(index int, source PropertySource) (stop bool) {
 			if !env.propertySources.Contains(source.GetName()) {
 				env.propertySources.AddLast(source)
@@ -307,12 +334,12 @@ func New(options ...Option) *StandardEnvironment {
 }
 
 func (s *StandardEnvironment) InitPropertyResolver() {
-	if s.propertySources == nil || s.propertyResolver == nil {
+	s.resolverOnce.Do(func() {
 		s.propertyResolver = &PropertySourcesPropertyResolver{
-			propertySources:                      s.propertySources,
+			propertySources:                      s.GetPropertySources(),
 			ignoreUnresolvableNestedPlaceholders: s.ignoreUnresolvableNestedPlaceholders,
 		}
-	}
+	})
 }
 
 func (s *StandardEnvironment) ContainsProperty(key string) bool {
@@ -345,16 +372,26 @@ func (s *StandardEnvironment) ResolveRequiredPlaceholders(text string) string {
 	return s.propertyResolver.ResolveRequiredPlaceholders(text)
 }
 
+/**
+获取支持类型转换的配置解析器，如：GetTypedPropertyResolver().GetDurationWithDef("http.timeout", 30*time.Second)
+*/
+func (s *StandardEnvironment) GetTypedPropertyResolver() TypedPropertyResolver {
+	s.InitPropertyResolver()
+	return s.propertyResolver
+}
+
 func (s *StandardEnvironment) GetActiveProfiles() []string {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
 	return s.activeProfiles
 }
 
 func (s *StandardEnvironment) GetPropertySources() *MutablePropertySources {
-	if nil == s.propertySources {
-		s.propertySources = &MutablePropertySources{
-			propertySourceList: make([]PropertySource, 0),
+	s.sourcesOnce.Do(func() {
+		if nil == s.propertySources {
+			s.propertySources = NewMutablePropertySources()
 		}
-	}
+	})
 	return s.propertySources
 }
 
@@ -365,32 +402,39 @@ func (s *StandardEnvironment) Merge(parent Environment) {
 
 	parentSources := parent.GetPropertySources()
 	if parentSources != nil {
-		if s.propertySources == nil {
-			s.GetPropertySources()
-		}
+		propertySources := s.GetPropertySources()
 		parentSources.Each(func(index int, source PropertySource) (stop bool) {
-			if !s.propertySources.Contains(source.GetName()) {
-				s.propertySources.AddLast(source)
+			if !propertySources.Contains(source.GetName()) {
+				propertySources.AddLast(source)
 			}
 			return false
 		})
 	}
-	// 添加激活的配置文件
+	// 添加激活的配置文件，复制一份新的列表，已经返回给调用方的列表不会被修改
 	parentActiveProfiles := parent.GetActiveProfiles()
 	if len(parentActiveProfiles) > 0 {
-		if s.activeProfiles == nil {
-			s.activeProfiles = parentActiveProfiles
-		} else {
-			s.activeProfiles = append(s.activeProfiles, parentActiveProfiles...)
-		}
+		s.lock.Lock()
+		activeProfiles := make([]string, 0, len(s.activeProfiles)+len(parentActiveProfiles))
+		activeProfiles = append(activeProfiles, s.activeProfiles...)
+		s.activeProfiles = append(activeProfiles, parentActiveProfiles...)
+		s.lock.Unlock()
 	}
 }
 
 func (s *StandardEnvironment) Subscribe(keyPattern string, handler func(event *KeyChangeEvent)) {
-	if s.propertyChangeListeners == nil {
-		s.propertyChangeListeners = make([]*PropertyChangeListener, 0)
-	}
-	s.propertyChangeListeners = append(s.propertyChangeListeners, NewPropertyChangeListener(keyPattern, handler))
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	listeners := make([]*PropertyChangeListener, 0, len(s.propertyChangeListeners)+1)
+	listeners = append(listeners, s.propertyChangeListeners...)
+	s.propertyChangeListeners = append(listeners, NewPropertyChangeListener(keyPattern, handler))
+}
+
+func (s *StandardEnvironment) SubscribeChangeSet(handler func(changes *ChangeSet)) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	listeners := make([]func(changes *ChangeSet), 0, len(s.changeSetListeners)+1)
+	listeners = append(listeners, s.changeSetListeners...)
+	s.changeSetListeners = append(listeners, handler)
 }
 
 func (s *StandardEnvironment) refresh() {
@@ -398,25 +442,54 @@ func (s *StandardEnvironment) refresh() {
 }
 
 func (s *StandardEnvironment) initPropertySourceListen() {
-	// 执行所有配置来源的监听
+	// 执行所有配置来源的监听，支持批量发布的配置来源一次刷新作为一个 ChangeSet 处理
 	s.propertySources.Each(func(index int, source PropertySource) (stop bool) {
+		if publisher, ok := source.(ChangeSetPublisher); ok {
+			publisher.SubscribeChangeSet(func(changes *ChangeSet) {
+				logger.Info("收到配置来源["+source.GetName()+"]的配置变更：", changes)
+				s.onChangeSet(source, changes)
+			})
+			return false
+		}
 		source.Subscribe("*", func() func(event *KeyChangeEvent) {
 			return func(event *KeyChangeEvent) {
 				logger.Info("收到配置来源["+source.GetName()+"]的配置变更事件：", event)
-				s.onKeyChangeEvent(source, event)
+				s.onChangeSet(source, &ChangeSet{Source: source.GetName(), Changes: []*KeyChangeEvent{event}})
 			}
 		}())
 		return false
 	})
 }
 
+/**
+批量变更处理，先整体刷新配置Bean，然后执行 Key 变更监听器，最后执行批量变更监听器
+*/
+func (s *StandardEnvironment) onChangeSet(source PropertySource, changes *ChangeSet) {
+	s.changeLock.Lock()
+	defer s.changeLock.Unlock()
+
+	s.lock.RLock()
+	boundList, keyListeners, changeSetListeners := s.boundProperties, s.propertyChangeListeners, s.changeSetListeners
+	s.lock.RUnlock()
+
+	for _, bound := range boundList {
+		s.refreshBoundProperties(bound, changes)
+	}
+	for _, event := range changes.Changes {
+		s.onKeyChangeEvent(keyListeners, event)
+	}
+	for _, handler := range changeSetListeners {
+		handler(changes)
+	}
+}
+
 /**
 Key 变更处理
 */
-func (s *StandardEnvironment) onKeyChangeEvent(source PropertySource, event *KeyChangeEvent) {
+func (s *StandardEnvironment) onKeyChangeEvent(listeners []*PropertyChangeListener, event *KeyChangeEvent) {
 	// 执行监听器
-	if len(s.propertyChangeListeners) > 0 {
-		for _, listener := range s.propertyChangeListeners {
+	if len(listeners) > 0 {
+		for _, listener := range listeners {
 			keyPattern := listener.KeyPattern
 			handler := listener.Handler
 			if handler == nil {
@@ -453,11 +526,15 @@ func (s *StandardEnvironment) doBindProperties(keyPrefix string, cfgPtr interfac
 
 	if listen {
 		// 已经绑定过了
-		if bean, ok := s.bindBeans[t]; ok {
-			return bean, nil
-		} else {
+		s.lock.Lock()
+		bean, ok := s.bindBeans[t]
+		if !ok {
 			s.bindBeans[t] = cfgPtr
 		}
+		s.lock.Unlock()
+		if ok {
+			return bean, nil
+		}
 	}
 
 	v := reflect.ValueOf(cfgPtr)
@@ -465,42 +542,26 @@ func (s *StandardEnvironment) doBindProperties(keyPrefix string, cfgPtr interfac
 		v = v.Elem()
 	}
 
-	for i := 0; i < t.NumField(); i++ {
-		tfield := t.Field(i)
-		vfield := v.Field(i)
-
-		fieldName := tfield.Name
-
-		// 默认是首字母小写
-		configKey := keyPrefix + StringUtils.FirstLetterLower(fieldName)
-		subKey := tfield.Tag.Get("ck")
-		if len(subKey) < 1 {
-			subKey = tfield.Tag.Get("sk")
-		}
-		if len(subKey) > 0 {
-			configKey = keyPrefix + subKey
-		}
-
-		// 初始值
-		initVal := tfield.Tag.Get("def")
-
-		// 获取配置的值
-		value, exists := s.GetProperty(configKey)
-		if !exists {
-			value = s.ResolvePlaceholders(initVal)
-		}
-		// 反射进行配置回写
-		s.applyBeanPropertyValue(t, tfield, vfield, initVal, value, PropertyUpdate)
-
+	binder := &propertyBinder{}
+	s.bindStruct(keyPrefix, t, v, false, binder)
+	binder.violations = append(binder.violations, s.validateStruct(keyPrefix, t, v)...)
+	if len(binder.violations) > 0 {
 		if listen {
-			// 注册监听器, 占位符问题，每次变更的话，都需要重新检查占位符，当占位符变化这个也要变化
-			s.Subscribe(configKey, func() func(event *KeyChangeEvent) {
-				return func(event *KeyChangeEvent) {
-					s.applyBeanPropertyValue(t, tfield, vfield, initVal, event.Nv, event.ChangeType)
-				}
-			}())
+			s.lock.Lock()
+			delete(s.bindBeans, t)
+			s.lock.Unlock()
 		}
+		return nil, &PropertyValidationError{Target: t.String(), Violations: binder.violations}
+	}
+	if listen {
+		// 校验通过之后再监听，配置变更时整体刷新
+		s.lock.Lock()
+		boundList := make([]*boundProperties, 0, len(s.boundProperties)+1)
+		boundList = append(boundList, s.boundProperties...)
+		s.boundProperties = append(boundList, &boundProperties{keyPrefix: keyPrefix, beanType: t, bean: reflect.ValueOf(cfgPtr), current: newPropertiesSnapshot(reflect.ValueOf(cfgPtr))})
+		s.lock.Unlock()
 	}
+
 	jsonText, err := json.Marshal(cfgPtr)
 	if err != nil {
 		return nil, err
@@ -522,29 +583,42 @@ func (s *StandardEnvironment) GetProperties(typeTemplate interface{}) (beanPtr i
 		ptype = ptype.Elem()
 	}
 
+	s.lock.RLock()
+	defer s.lock.RUnlock()
 	if bean, ok := s.bindBeans[ptype]; ok {
 		return bean
 	}
 	return nil
 }
 
-func (s *StandardEnvironment) applyBeanPropertyValue(beanType reflect.Type, tfield reflect.StructField, vfield reflect.Value, initVal string, value string, changeType KeyChangeType) {
+/**
+获取监听配置变化的配置Bean的快照，没有监听的话返回 nil
+*/
+func (s *StandardEnvironment) propertiesSnapshot(t reflect.Type) *atomic.Value {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	for _, bound := range s.boundProperties {
+		if bound.beanType == t {
+			return bound.current
+		}
+	}
+	return nil
+}
+
+func (s *StandardEnvironment) applyBeanPropertyValue(beanType reflect.Type, tfield reflect.StructField, vfield reflect.Value, initVal string, value string, changeType KeyChangeType) (err error) {
 	if PropertyDel == changeType {
 		// 删除，设置回原来的初始值
 		value = initVal
 	}
 
-	var cerr error
-
 	defer func() {
 		if r := recover(); r != nil {
-			logger.Error("配置转换异常：panic,Property:["+beanType.Name()+"."+tfield.Name+":"+tfield.Type.Name()+"], newVal:["+value+"]", r)
-		} else {
-			if cerr != nil {
-				logger.Error("配置转换失败,Property:["+beanType.Name()+"."+tfield.Name+":"+tfield.Type.Name()+"], newVal:["+value+"]", cerr)
-			}
+			err = fmt.Errorf("配置转换异常：panic,Property:[%s.%s:%s], newVal:[%s], %v", beanType.Name(), tfield.Name, tfield.Type.Name(), value, r)
 		}
 	}()
 
-	cerr = ReflectUtils.SetFieldValueByField(tfield, vfield, value)
+	if cerr := ReflectUtils.SetFieldValueByField(tfield, vfield, value); cerr != nil {
+		err = errors.New("配置转换失败,Property:[" + beanType.Name() + "." + tfield.Name + ":" + tfield.Type.Name() + "], newVal:[" + value + "], " + cerr.Error())
+	}
+	return
 }
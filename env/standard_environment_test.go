@@ -159,3 +159,22 @@ func TestStandardEnvironment_BindPropertiesListen(t *testing.T) {
 	assert.Equal(t, int64(0), config.PageSize)
 
 }
+
+func TestBind(t *testing.T) {
+	additionalPropertySources := NewMutablePropertySources(
+		NewMapPropertySource("test", map[string]string{
+			"user.id":       "2",
+			"user.username": "Hello_${user.id}",
+		}),
+	)
+
+	env := New(AdditionalPropertySources(additionalPropertySources))
+	user, err := Bind[UserInfo](env, "user.")
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, user.Id)
+	assert.Equal(t, "Hello_2", user.username)
+
+	_, err = Bind[string](env, "user.")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,284 @@
+package env
+
+import (
+	"errors"
+	"github.com/xkgo/sparrow/logger"
+	"github.com/xkgo/sparrow/util/ConvertUtils"
+	"github.com/xkgo/sparrow/util/ReflectUtils"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/**
+GetTime 支持的时间格式，不带时区的按照本地时区解析
+*/
+var propertyTimeLayouts = []string{time.RFC3339Nano, "2006-01-02 15:04:05", "2006-01-02T15:04:05", "2006-01-02"}
+
+/**
+配置项类型转换失败
+*/
+type PropertyConvertError struct {
+	Key    string // 配置 key
+	Value  string // 配置的原始值（已处理占位符）
+	Source string // 配置来源名称
+	Type   string // 目标类型
+	Err    error  // 转换失败原因
+}
+
+func (e *PropertyConvertError) Error() string {
+	return "配置项" + e.Key + "=[" + e.Value + "](来源: " + e.Source + ")无法转换为 " + e.Type + ": " + e.Err.Error()
+}
+
+func (e *PropertyConvertError) Unwrap() error {
+	return e.Err
+}
+
+/**
+获取 Environment 的类型转换配置解析器，没有提供的话基于其配置来源创建
+*/
+func TypedResolverOf(environment Environment) TypedPropertyResolver {
+	if resolver, ok := environment.(TypedPropertyResolver); ok {
+		return resolver
+	}
+	if provider, ok := environment.(interface {
+		GetTypedPropertyResolver() TypedPropertyResolver
+	}); ok {
+		return provider.GetTypedPropertyResolver()
+	}
+	return NewPropertySourcesPropertyResolver(environment.GetPropertySources(), false)
+}
+
+/**
+读取配置项并转换类型，配置项不存在的话 exists 为 false，转换失败返回 PropertyConvertError
+*/
+func getTypedProperty[T any](p *PropertySourcesPropertyResolver, key, typeName string, convert func(raw string) (T, error)) (value T, exists bool, err error) {
+	raw, source, exists := p.doGetPropertyWithSource(key, true)
+	if !exists {
+		return
+	}
+	value, cerr := convert(raw)
+	if cerr != nil {
+		err = &PropertyConvertError{Key: key, Value: raw, Source: source, Type: typeName, Err: cerr}
+	}
+	return
+}
+
+/**
+配置项不存在或者转换失败的时候返回默认值，转换失败会打印告警日志
+*/
+func typedPropertyWithDef[T any](value T, exists bool, err error, def T) T {
+	if err != nil {
+		logger.Warn(err.Error(), ", 使用默认值：", def)
+		return def
+	}
+	if !exists {
+		return def
+	}
+	return value
+}
+
+/**
+配置项不存在或者转换失败的时候直接 panic
+*/
+func requiredTypedProperty[T any](key string, value T, exists bool, err error) T {
+	if err != nil {
+		panic(err)
+	}
+	if !exists {
+		panic("Required key '" + key + "' not found")
+	}
+	return value
+}
+
+func (p *PropertySourcesPropertyResolver) GetInt(key string) (value int, exists bool, err error) {
+	return getTypedProperty(p, key, "int", ConvertUtils.ToInt)
+}
+
+func (p *PropertySourcesPropertyResolver) GetIntWithDef(key string, def int) int {
+	value, exists, err := p.GetInt(key)
+	return typedPropertyWithDef(value, exists, err, def)
+}
+
+func (p *PropertySourcesPropertyResolver) GetRequiredInt(key string) int {
+	value, exists, err := p.GetInt(key)
+	return requiredTypedProperty(key, value, exists, err)
+}
+
+func (p *PropertySourcesPropertyResolver) GetInt64(key string) (value int64, exists bool, err error) {
+	return getTypedProperty(p, key, "int64", ConvertUtils.ToInt64)
+}
+
+func (p *PropertySourcesPropertyResolver) GetInt64WithDef(key string, def int64) int64 {
+	value, exists, err := p.GetInt64(key)
+	return typedPropertyWithDef(value, exists, err, def)
+}
+
+func (p *PropertySourcesPropertyResolver) GetRequiredInt64(key string) int64 {
+	value, exists, err := p.GetInt64(key)
+	return requiredTypedProperty(key, value, exists, err)
+}
+
+func (p *PropertySourcesPropertyResolver) GetBool(key string) (value bool, exists bool, err error) {
+	return getTypedProperty(p, key, "bool", ConvertUtils.ToBool)
+}
+
+func (p *PropertySourcesPropertyResolver) GetBoolWithDef(key string, def bool) bool {
+	value, exists, err := p.GetBool(key)
+	return typedPropertyWithDef(value, exists, err, def)
+}
+
+func (p *PropertySourcesPropertyResolver) GetRequiredBool(key string) bool {
+	value, exists, err := p.GetBool(key)
+	return requiredTypedProperty(key, value, exists, err)
+}
+
+func (p *PropertySourcesPropertyResolver) GetFloat64(key string) (value float64, exists bool, err error) {
+	return getTypedProperty(p, key, "float64", ConvertUtils.ToFloat64)
+}
+
+func (p *PropertySourcesPropertyResolver) GetFloat64WithDef(key string, def float64) float64 {
+	value, exists, err := p.GetFloat64(key)
+	return typedPropertyWithDef(value, exists, err, def)
+}
+
+func (p *PropertySourcesPropertyResolver) GetRequiredFloat64(key string) float64 {
+	value, exists, err := p.GetFloat64(key)
+	return requiredTypedProperty(key, value, exists, err)
+}
+
+/**
+转换规则和绑定配置Bean的 time.Duration 属性一致：支持 30s、1m 等格式，纯数字的话单位是纳秒
+*/
+func (p *PropertySourcesPropertyResolver) GetDuration(key string) (value time.Duration, exists bool, err error) {
+	return getTypedProperty(p, key, "time.Duration", func(raw string) (time.Duration, error) {
+		if len(ConvertUtils.TrimBlank(raw)) < 1 {
+			return 0, errors.New("时间间隔不能为空")
+		}
+		converted, err := ReflectUtils.ConvertTo(raw, reflect.TypeOf(ReflectUtils.DefDuration))
+		if err != nil {
+			return 0, err
+		}
+		return converted.Interface().(time.Duration), nil
+	})
+}
+
+func (p *PropertySourcesPropertyResolver) GetDurationWithDef(key string, def time.Duration) time.Duration {
+	value, exists, err := p.GetDuration(key)
+	return typedPropertyWithDef(value, exists, err, def)
+}
+
+func (p *PropertySourcesPropertyResolver) GetRequiredDuration(key string) time.Duration {
+	value, exists, err := p.GetDuration(key)
+	return requiredTypedProperty(key, value, exists, err)
+}
+
+/**
+配置值可以是 JSON 数组，也可以是英文逗号分隔的字符串（去掉首尾空白）；
+key 本身没有配置的话，读取 key[0]、key[1] 这种下标形式的配置项
+*/
+func (p *PropertySourcesPropertyResolver) GetStringSlice(key string) (value []string, exists bool, err error) {
+	value, exists, err = getTypedProperty(p, key, "[]string", func(raw string) ([]string, error) {
+		if len(strings.TrimSpace(raw)) < 1 {
+			return make([]string, 0), nil
+		}
+		converted, err := convertPropertyValue(raw, reflect.TypeOf(value))
+		if err != nil {
+			return nil, err
+		}
+		return converted.Interface().([]string), nil
+	})
+	if exists || nil == p.propertySources {
+		return
+	}
+
+	prefix := key + "["
+	size := 0
+	for _, k := range propertyKeysWithPrefix(p.propertySources, prefix) {
+		if !strings.HasSuffix(k, "]") {
+			continue
+		}
+		index, perr := strconv.Atoi(k[len(prefix) : len(k)-1])
+		if perr != nil || index < 0 {
+			continue
+		}
+		if index+1 > size {
+			size = index + 1
+		}
+	}
+	if size < 1 {
+		return
+	}
+	value = make([]string, size)
+	for i := 0; i < size; i++ {
+		value[i], _ = p.doGetProperty(key+"["+strconv.Itoa(i)+"]", true)
+	}
+	return value, true, nil
+}
+
+func (p *PropertySourcesPropertyResolver) GetStringSliceWithDef(key string, def []string) []string {
+	value, exists, err := p.GetStringSlice(key)
+	return typedPropertyWithDef(value, exists, err, def)
+}
+
+func (p *PropertySourcesPropertyResolver) GetRequiredStringSlice(key string) []string {
+	value, exists, err := p.GetStringSlice(key)
+	return requiredTypedProperty(key, value, exists, err)
+}
+
+/**
+读取所有以 key. 开头的配置项，key. 之后的部分作为 map 的 key，如：labels.app=order 读取 labels 得到 {app: order}，
+没有任何这样的配置项的话 exists 为 false
+*/
+func (p *PropertySourcesPropertyResolver) GetStringMap(key string) (value map[string]string, exists bool, err error) {
+	if nil == p.propertySources {
+		return
+	}
+	prefix := key + "."
+	keys := propertyKeysWithPrefix(p.propertySources, prefix)
+	if len(keys) < 1 {
+		return
+	}
+	value = make(map[string]string, len(keys))
+	for _, k := range keys {
+		value[k[len(prefix):]], _ = p.doGetProperty(k, true)
+	}
+	return value, true, nil
+}
+
+func (p *PropertySourcesPropertyResolver) GetStringMapWithDef(key string, def map[string]string) map[string]string {
+	value, exists, err := p.GetStringMap(key)
+	return typedPropertyWithDef(value, exists, err, def)
+}
+
+func (p *PropertySourcesPropertyResolver) GetRequiredStringMap(key string) map[string]string {
+	value, exists, err := p.GetStringMap(key)
+	return requiredTypedProperty(key, value, exists, err)
+}
+
+/**
+支持的格式：2006-01-02T15:04:05Z07:00（RFC3339）、2006-01-02 15:04:05、2006-01-02T15:04:05、2006-01-02，
+秒后面可以带小数，不带时区的按照本地时区解析
+*/
+func (p *PropertySourcesPropertyResolver) GetTime(key string) (value time.Time, exists bool, err error) {
+	return getTypedProperty(p, key, "time.Time", func(raw string) (time.Time, error) {
+		raw = strings.TrimSpace(raw)
+		for _, layout := range propertyTimeLayouts {
+			if t, err := time.ParseInLocation(layout, raw, time.Local); err == nil {
+				return t, nil
+			}
+		}
+		return time.Time{}, errors.New("不支持的时间格式，支持：" + strings.Join(propertyTimeLayouts, "、"))
+	})
+}
+
+func (p *PropertySourcesPropertyResolver) GetTimeWithDef(key string, def time.Time) time.Time {
+	value, exists, err := p.GetTime(key)
+	return typedPropertyWithDef(value, exists, err, def)
+}
+
+func (p *PropertySourcesPropertyResolver) GetRequiredTime(key string) time.Time {
+	value, exists, err := p.GetTime(key)
+	return requiredTypedProperty(key, value, exists, err)
+}
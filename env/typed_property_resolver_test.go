@@ -0,0 +1,116 @@
+package env
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func newTypedPropertyResolver() *PropertySourcesPropertyResolver {
+	return NewPropertySourcesPropertyResolver(NewMutablePropertySources(
+		NewMapPropertySource("override", map[string]string{
+			"server.port": "${default.port}",
+		}),
+		NewMapPropertySource("typed", map[string]string{
+			"default.port":       "8080",
+			"server.max-bytes":   "10737418240",
+			"server.debug":       "on",
+			"server.ratio":       "0.75",
+			"server.timeout":     "1m30s",
+			"server.name":        "order",
+			"server.hosts":       "10.0.0.1, 10.0.0.2,10.0.0.3",
+			"server.tags":        "[\"a\",\"b\"]",
+			"server.zones[0]":    "wuxi",
+			"server.zones[1]":    "shanghai",
+			"server.labels.app":  "order",
+			"server.labels.team": "trade",
+			"server.empty":       "",
+			"server.start":       "2026-01-02 15:04:05",
+			"server.expire":      "2026-01-02T15:04:05+08:00",
+		}),
+	), true)
+}
+
+func TestPropertySourcesPropertyResolver_TypedAccessors(t *testing.T) {
+	r := newTypedPropertyResolver()
+
+	port, exists, err := r.GetInt("server.port")
+	assert.Nil(t, err)
+	assert.True(t, exists)
+	assert.Equal(t, 8080, port)
+
+	_, exists, err = r.GetInt("server.missing")
+	assert.Nil(t, err)
+	assert.False(t, exists)
+
+	assert.Equal(t, int64(10737418240), r.GetRequiredInt64("server.max-bytes"))
+	assert.True(t, r.GetRequiredBool("server.debug"))
+	assert.Equal(t, 0.75, r.GetRequiredFloat64("server.ratio"))
+	assert.Equal(t, 90*time.Second, r.GetRequiredDuration("server.timeout"))
+
+	assert.Equal(t, []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}, r.GetRequiredStringSlice("server.hosts"))
+	assert.Equal(t, []string{"a", "b"}, r.GetRequiredStringSlice("server.tags"))
+	assert.Equal(t, []string{"wuxi", "shanghai"}, r.GetRequiredStringSlice("server.zones"))
+	assert.Equal(t, []string{}, r.GetRequiredStringSlice("server.empty"))
+	assert.Equal(t, map[string]string{"app": "order", "team": "trade"}, r.GetRequiredStringMap("server.labels"))
+
+	start := r.GetRequiredTime("server.start")
+	assert.Equal(t, time.Date(2026, 1, 2, 15, 4, 5, 0, time.Local), start)
+	expire := r.GetRequiredTime("server.expire")
+	assert.Equal(t, time.Date(2026, 1, 2, 7, 4, 5, 0, time.UTC), expire.UTC())
+
+	// 默认值
+	assert.Equal(t, 80, r.GetIntWithDef("server.missing", 80))
+	assert.Equal(t, 80, r.GetIntWithDef("server.name", 80))
+	assert.Equal(t, []string{"x"}, r.GetStringSliceWithDef("server.missing", []string{"x"}))
+	assert.Equal(t, map[string]string{"k": "v"}, r.GetStringMapWithDef("server.missing", map[string]string{"k": "v"}))
+	assert.Equal(t, time.Second, r.GetDurationWithDef("server.name", time.Second))
+	assert.False(t, r.GetBoolWithDef("server.missing", false))
+}
+
+func TestPropertySourcesPropertyResolver_TypedAccessorsError(t *testing.T) {
+	r := newTypedPropertyResolver()
+
+	_, exists, err := r.GetInt("server.name")
+	assert.True(t, exists)
+	var convertErr *PropertyConvertError
+	assert.True(t, errors.As(err, &convertErr))
+	assert.Equal(t, "server.name", convertErr.Key)
+	assert.Equal(t, "order", convertErr.Value)
+	assert.Equal(t, "typed", convertErr.Source)
+	assert.Contains(t, err.Error(), "server.name=[order](来源: typed)无法转换为 int")
+
+	_, _, err = r.GetTime("server.name")
+	assert.Contains(t, err.Error(), "server.name=[order](来源: typed)无法转换为 time.Time")
+
+	// 占位符处理之后的值，来源为 key 所在的配置来源
+	_, _, err = NewPropertySourcesPropertyResolver(NewMutablePropertySources(
+		NewMapPropertySource("override", map[string]string{"server.debug": "${server.name}"}),
+		NewMapPropertySource("typed", map[string]string{"server.name": "order"}),
+	), true).GetBool("server.debug")
+	assert.Contains(t, err.Error(), "server.debug=[order](来源: override)")
+
+	_, _, err = r.GetInt64("server.ratio")
+	assert.PanicsWithError(t, err.Error(), func() {
+		r.GetRequiredInt64("server.ratio")
+	})
+	assert.PanicsWithValue(t, "Required key 'server.missing' not found", func() {
+		r.GetRequiredDuration("server.missing")
+	})
+}
+
+func TestStandardEnvironment_TypedAccessors(t *testing.T) {
+	env := New(AdditionalPropertySources(NewMutablePropertySources(NewMapPropertySource("typed", map[string]string{
+		"typed.port":    "9090",
+		"typed.timeout": "30s",
+	}))))
+	resolver := env.GetTypedPropertyResolver()
+	assert.Equal(t, 9090, resolver.GetRequiredInt("typed.port"))
+	assert.Equal(t, 30*time.Second, resolver.GetDurationWithDef("typed.timeout", time.Second))
+	assert.Equal(t, time.Second, resolver.GetDurationWithDef("typed.missing", time.Second))
+	assert.Equal(t, resolver, TypedResolverOf(env))
+
+	// 实现了 PropertyResolver 的解析器不受影响
+	var _ PropertyResolver = env
+}
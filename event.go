@@ -0,0 +1,160 @@
+package sparrow
+
+import (
+	"github.com/xkgo/sparrow/logger"
+	"github.com/xkgo/sparrow/util/GoUtils"
+	"reflect"
+	"sort"
+)
+
+/**
+环境准备完成（模块默认配置已经添加），Bean 还没有开始注入
+*/
+type EnvironmentPreparedEvent struct {
+	App *Application
+}
+
+/**
+所有 Bean 已经完成注入以及初始化
+*/
+type BeansWiredEvent struct {
+	App *Application
+}
+
+/**
+程序已经启动，Runner 执行之前
+*/
+type ApplicationStartedEvent struct {
+	App *Application
+}
+
+/**
+Runner 已经执行（ContextRunner 已经启动），程序可以对外提供服务
+*/
+type ApplicationReadyEvent struct {
+	App *Application
+}
+
+/**
+开始执行优雅退出
+*/
+type ShutdownStartedEvent struct {
+	App *Application
+}
+
+/**
+异步事件监听器，监听器 Bean 实现这个接口并且返回 true 的话，事件会在单独的 Goroutine 中处理
+*/
+type AsyncEventListener interface {
+	IsAsync() bool
+}
+
+// 事件监听方法名称，格式：OnEvent(event T)，按照参数类型匹配事件
+const eventListenerMethodName = "OnEvent"
+
+type eventListener struct {
+	name    string        // 监听器名称
+	handler reflect.Value // 事件处理方法
+	async   bool          // 是否异步执行
+}
+
+/**
+添加事件监听器，可以在 Bean 初始化之前接收事件（比如 EnvironmentPreparedEvent）
+@param listener 实现了 OnEvent(event T) 方法的对象，或者是 func(event T) 函数
+*/
+func WithEventListeners(listeners ...interface{}) Option {
+	return func(app *Application) {
+		for _, listener := range listeners {
+			app.AddEventListener(listener)
+		}
+	}
+}
+
+/**
+添加事件监听器
+@param listener 实现了 OnEvent(event T) 方法的对象，或者是 func(event T) 函数
+*/
+func (a *Application) AddEventListener(listener interface{}) {
+	el := newEventListener(reflect.TypeOf(listener).String(), listener)
+	if el == nil {
+		logger.Warn("非法的事件监听器，必须是 func(event T) 或者实现了 OnEvent(event T) 方法: ", reflect.TypeOf(listener))
+		return
+	}
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.eventListeners = append(a.eventListeners, el)
+}
+
+func newEventListener(name string, listener interface{}) *eventListener {
+	if listener == nil {
+		return nil
+	}
+	handler := reflect.ValueOf(listener)
+	if handler.Kind() != reflect.Func {
+		handler = handler.MethodByName(eventListenerMethodName)
+	}
+	if !handler.IsValid() || handler.Type().NumIn() != 1 {
+		return nil
+	}
+	el := &eventListener{name: name, handler: handler}
+	if async, ok := listener.(AsyncEventListener); ok {
+		el.async = async.IsAsync()
+	}
+	return el
+}
+
+/**
+发布事件，按照 OnEvent 的参数类型匹配监听器：先是通过 AddEventListener 添加的，然后是已经初始化完成的单例 Bean（按照名称排序），
+同步监听器发生 panic 不会影响其他监听器
+*/
+func (a *Application) Publish(event interface{}) {
+	if event == nil {
+		return
+	}
+	eventType := reflect.TypeOf(event)
+	eventValue := reflect.ValueOf(event)
+	for _, listener := range a.getEventListeners() {
+		if !eventType.AssignableTo(listener.handler.Type().In(0)) {
+			continue
+		}
+		l := listener
+		handle := func() {
+			rets := l.handler.Call([]reflect.Value{eventValue})
+			for _, ret := range rets {
+				if err, ok := ret.Interface().(error); ok && err != nil {
+					logger.Error("事件监听器["+l.name+"]处理事件[", eventType, "]失败: ", err)
+				}
+			}
+		}
+		onPanic := func(r interface{}) {
+			logger.Error("事件监听器["+l.name+"]处理事件[", eventType, "]发生panic: ", r)
+		}
+		if l.async {
+			runGoroutine(handle, onPanic)
+		} else {
+			GoUtils.Run(handle, onPanic)
+		}
+	}
+}
+
+func (a *Application) getEventListeners() []*eventListener {
+	a.lock.RLock()
+	listeners := append(make([]*eventListener, 0, len(a.eventListeners)), a.eventListeners...)
+	names := make([]string, 0)
+	beans := make(map[string]interface{})
+	for name, bd := range a.container {
+		if bd.Ready && bd.IsSingleton() {
+			names = append(names, name)
+			beans[name] = bd.Bean
+		}
+	}
+	a.lock.RUnlock()
+
+	sort.Strings(names)
+	for _, name := range names {
+		if el := newEventListener(name, beans[name]); el != nil {
+			listeners = append(listeners, el)
+		}
+	}
+	return listeners
+}
@@ -0,0 +1,84 @@
+package sparrow
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/xkgo/sparrow/env"
+	"testing"
+)
+
+type OrderCreatedEvent struct {
+	OrderId int
+}
+
+type OrderAudit struct {
+	orders []int
+}
+
+func (o *OrderAudit) OnEvent(event *OrderCreatedEvent) {
+	o.orders = append(o.orders, event.OrderId)
+}
+
+type LifecycleRecorder struct {
+	events []string
+}
+
+func (l *LifecycleRecorder) OnEvent(event interface{}) {
+	switch event.(type) {
+	case *BeansWiredEvent:
+		l.events = append(l.events, "BeansWired")
+	case *ApplicationStartedEvent:
+		l.events = append(l.events, "Started")
+	case *ApplicationReadyEvent:
+		l.events = append(l.events, "Ready")
+	case *ShutdownStartedEvent:
+		l.events = append(l.events, "ShutdownStarted")
+	}
+}
+
+type AsyncOrderAudit struct {
+	done chan int
+}
+
+func (o *AsyncOrderAudit) IsAsync() bool {
+	return true
+}
+
+func (o *AsyncOrderAudit) OnEvent(event *OrderCreatedEvent) {
+	o.done <- event.OrderId
+}
+
+func TestApplication_Publish(t *testing.T) {
+	a := newTestApplication()
+	a.RegisterBean(&OrderAudit{}, "", true)
+	async := &AsyncOrderAudit{done: make(chan int, 1)}
+	a.RegisterBean(async, "", true)
+	assert.Nil(t, a.init())
+
+	funcOrders := make([]int, 0)
+	a.AddEventListener(func(event *OrderCreatedEvent) {
+		funcOrders = append(funcOrders, event.OrderId)
+		panic("listener failed")
+	})
+
+	a.Publish(&OrderCreatedEvent{OrderId: 1})
+	a.Publish("unmatched event")
+
+	assert.Equal(t, []int{1}, a.GetBeanByName("OrderAudit").(*OrderAudit).orders)
+	assert.Equal(t, []int{1}, funcOrders)
+	assert.Equal(t, 1, <-async.done)
+}
+
+func TestApplication_LifecycleEvents(t *testing.T) {
+	a := NewApplication()
+	recorder := &LifecycleRecorder{}
+	a.RegisterBean(recorder, "", true)
+
+	prepared := false
+	code := a.RunOnce(env.New(env.ConfigDirs("./testdata")), WithEventListeners(func(event *EnvironmentPreparedEvent) {
+		prepared = event.App == a
+	}))
+
+	assert.Equal(t, 0, code)
+	assert.True(t, prepared)
+	assert.Equal(t, []string{"BeansWired", "Started", "Ready", "ShutdownStarted"}, recorder.events)
+}
@@ -0,0 +1,54 @@
+package sparrow
+
+import (
+	"errors"
+	"strconv"
+)
+
+/**
+自定义程序退出码，Runner 返回的 error 实现了该接口的话，RunOnce 会使用 ExitCode() 作为进程退出码
+*/
+type ExitCoder interface {
+	ExitCode() int
+}
+
+/**
+带退出码的 error
+*/
+type ExitError struct {
+	Code int   // 退出码
+	Err  error // 原始错误
+}
+
+func NewExitError(code int, err error) *ExitError {
+	return &ExitError{Code: code, Err: err}
+}
+
+func (e *ExitError) Error() string {
+	if e.Err == nil {
+		return "exit code " + strconv.Itoa(e.Code)
+	}
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+func (e *ExitError) ExitCode() int {
+	return e.Code
+}
+
+/**
+根据 Runner 返回的 error 计算退出码：nil 为 0，实现了 ExitCoder 的使用其退出码，其他为 1
+*/
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var coder ExitCoder
+	if errors.As(err, &coder) {
+		return coder.ExitCode()
+	}
+	return 1
+}
@@ -0,0 +1,43 @@
+package sparrow
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/xkgo/sparrow/env"
+	"testing"
+)
+
+func TestExitCode(t *testing.T) {
+	assert.Equal(t, 0, ExitCode(nil))
+	assert.Equal(t, 1, ExitCode(errors.New("failed")))
+	assert.Equal(t, 3, ExitCode(NewExitError(3, errors.New("failed"))))
+	assert.Equal(t, 4, ExitCode(fmt.Errorf("wrapped: %w", NewExitError(4, nil))))
+}
+
+func TestRunOnce(t *testing.T) {
+	global := app
+	defer func() { app = global }()
+
+	destroyed = nil
+	app = NewApplication()
+	app.RegisterBean(&ConnPool{}, "", true)
+
+	executed := false
+	code := RunOnce(env.New(env.ConfigDirs("./testdata")), WithRunner(func(a *Application) (err error) {
+		executed = true
+		return nil
+	}))
+	assert.True(t, executed)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, []string{"ConnPool"}, destroyed)
+
+	destroyed = nil
+	app = NewApplication()
+	app.RegisterBean(&ConnPool{}, "", true)
+	code = RunOnce(env.New(env.ConfigDirs("./testdata")), WithRunner(func(a *Application) (err error) {
+		return NewExitError(2, errors.New("migration failed"))
+	}))
+	assert.Equal(t, 2, code)
+	assert.Equal(t, []string{"ConnPool"}, destroyed)
+}
@@ -0,0 +1,104 @@
+package sparrow
+
+import (
+	"errors"
+	"github.com/xkgo/sparrow/logger"
+	"github.com/xkgo/sparrow/util/ReflectUtils"
+	"reflect"
+	"sort"
+)
+
+/**
+获取类型为 T 的 Primary Bean，T 一般是接口或者指针类型，当前容器找不到的话会到父容器中查找
+@param app 为 nil 的话使用全局的 Application
+*/
+func Get[T any](app *Application) (bean T, err error) {
+	app = resolveApp(app)
+	beanType := reflect.TypeOf((*T)(nil)).Elem()
+	owner, bd, err := app.lookupPrimaryBeanDefinitionOfType(beanType, "")
+	if err != nil {
+		return bean, err
+	}
+	return getTypedBean[T](owner, bd)
+}
+
+/**
+获取类型为 T 的 Bean，找不到或者有多个且没有 Primary 的话直接 panic，一般用于启动阶段
+*/
+func MustGet[T any](app *Application) T {
+	bean, err := Get[T](app)
+	if err != nil {
+		panic(err)
+	}
+	return bean
+}
+
+/**
+获取指定名称（或者别名）的 Bean，并转换成 T 类型
+*/
+func GetNamed[T any](app *Application, name string) (bean T, err error) {
+	app = resolveApp(app)
+	owner, bd := app.lookupBeanDefinition(name)
+	if bd == nil {
+		return bean, errors.New("Bean[" + app.describeBeanName(name) + "]不存在")
+	}
+	return getTypedBean[T](owner, bd)
+}
+
+/**
+获取当前容器中所有类型为 T 的 Bean，与 Slice 注入一样按照 Order 从大到小排序，获取失败的 Bean 会被忽略
+*/
+func GetAll[T any](app *Application) []T {
+	app = resolveApp(app)
+	beanType := reflect.TypeOf((*T)(nil)).Elem()
+	bdList := make([]*BeanDefinition, 0)
+	for _, bd := range app.getBeanDefinitionsOfType(beanType) {
+		bdList = append(bdList, bd)
+	}
+	app.lock.RLock()
+	sort.Slice(bdList, func(i, j int) bool {
+		if bdList[i].Order != bdList[j].Order {
+			return bdList[j].Order < bdList[i].Order
+		}
+		return bdList[i].Name < bdList[j].Name
+	})
+	app.lock.RUnlock()
+
+	beans := make([]T, 0, len(bdList))
+	for _, bd := range bdList {
+		bean, err := getTypedBean[T](app, bd)
+		if err != nil {
+			logger.Error("获取Bean["+bd.Name+"]失败: ", err)
+			continue
+		}
+		beans = append(beans, bean)
+	}
+	return beans
+}
+
+func resolveApp(app *Application) *Application {
+	if app == nil {
+		return getApp()
+	}
+	return app
+}
+
+func getTypedBean[T any](owner *Application, bd *BeanDefinition) (bean T, err error) {
+	instance, err := owner.lookupBeanInstance(bd)
+	if err != nil {
+		return bean, err
+	}
+	beanType := reflect.TypeOf((*T)(nil)).Elem()
+	value, err := ReflectUtils.ConvertTo(instance, beanType)
+	if err != nil {
+		return bean, replacedBeanError(bd, instance, beanType, err)
+	}
+	if !value.IsValid() {
+		return bean, errors.New("Bean[" + bd.Name + "](" + bd.Type.String() + ")无法转换成类型: " + beanType.String())
+	}
+	bean, ok := value.Interface().(T)
+	if !ok {
+		return bean, errors.New("Bean[" + bd.Name + "](" + bd.Type.String() + ")无法转换成类型: " + beanType.String())
+	}
+	return bean, nil
+}
@@ -0,0 +1,34 @@
+package sparrow
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGenericAccessors(t *testing.T) {
+	a := newTestApplication()
+	a.RegisterBean(&MysqlDataSource{url: "master"}, "masterDataSource", true, WithAliases("db"))
+	a.RegisterBean(&MysqlDataSource{url: "slave"}, "slaveDataSource", false)
+	a.RegisterBean(&OrderRepo{table: "t_order"}, "orderRepo", true)
+	assert.Nil(t, a.init())
+
+	ds, err := Get[DataSource](a)
+	assert.Nil(t, err)
+	assert.Equal(t, "master", ds.Url())
+
+	repo := MustGet[*OrderRepo](a)
+	assert.Equal(t, "t_order", repo.table)
+
+	named, err := GetNamed[*MysqlDataSource](a, "db")
+	assert.Nil(t, err)
+	assert.Equal(t, "master", named.url)
+
+	_, err = GetNamed[*OrderRepo](a, "slaveDataSource")
+	assert.NotNil(t, err)
+	_, err = GetNamed[*OrderRepo](a, "missing")
+	assert.NotNil(t, err)
+
+	assert.Len(t, GetAll[DataSource](a), 2)
+	assert.Len(t, GetAll[*ConnPool](a), 0)
+	assert.Panics(t, func() { MustGet[*ConnPool](a) })
+}
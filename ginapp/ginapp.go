@@ -1,11 +1,15 @@
 package ginapp
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/xkgo/sparrow"
 	"github.com/xkgo/sparrow/env"
 	"github.com/xkgo/sparrow/logger"
+	"github.com/xkgo/sparrow/util/GoUtils"
+	"net/http"
 )
 
 type ServerProperties struct {
@@ -22,30 +26,53 @@ type GinServer struct {
 	Properties ServerProperties `@Inject:"required:true"`
 }
 
+/**
+启动 HTTP 服务，可以作为 sparrow.WithRunner 使用，程序退出（app.Context() 被取消）时停止服务
+*/
 func (g *GinServer) Run(app *sparrow.Application) (err error) {
-	return g.Router.Run(fmt.Sprintf(":%v", g.Properties.Port))
+	return g.RunContext(app.Context(), app)
 }
 
 /**
-运行程序
+启动 HTTP 服务，ctx 被取消（程序退出）时停止接收新请求，并等待处理中的请求完成
 */
-func Run(environment env.Environment, options ...sparrow.Option) (err error) {
-	// 服务器配置
-	sparrow.RegisterPropertiesBean(&ServerProperties{}, "", "server.", true)
-
-	// 控制器注册
-	sparrow.RegisterBean(&GinRegistry{}, "gin_GinRegistry", true)
+func (g *GinServer) RunContext(ctx context.Context, app *sparrow.Application) (err error) {
+	server := &http.Server{
+		Addr:    fmt.Sprintf(":%v", g.Properties.Port),
+		Handler: g.Router,
+	}
 
-	sparrow.RegisterBean(gin.New(), "gin_Engine", true)
+	serveErr := make(chan error, 1)
+	GoUtils.RunGoroutine(func() {
+		logger.Info("启动 HTTP 服务, 监听地址: ", server.Addr)
+		serveErr <- server.ListenAndServe()
+	}, func(r interface{}) {
+		serveErr <- fmt.Errorf("HTTP 服务发生panic: %v", r)
+	})
 
-	ginServer := &GinServer{}
-	sparrow.RegisterBean(ginServer, "", true)
+	select {
+	case err = <-serveErr:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	case <-ctx.Done():
+		logger.Info("停止 HTTP 服务: ", server.Addr)
+		// 超时由 sparrow.shutdown.timeout 控制，超时后进程会被强制退出
+		return server.Shutdown(context.Background())
+	}
+}
 
+/**
+运行程序
+*/
+func Run(environment env.Environment, options ...sparrow.Option) (err error) {
 	if options == nil {
 		options = make([]sparrow.Option, 0)
 	}
+	module := NewModule()
 	// 启动
-	options = append(options, sparrow.WithRunner(ginServer.Run))
+	options = append(options, sparrow.WithModules(module), sparrow.WithContextRunner(module.Run))
 
 	err = sparrow.Run(environment, options...)
 	if nil != err {
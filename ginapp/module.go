@@ -0,0 +1,57 @@
+package ginapp
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/xkgo/sparrow"
+)
+
+// 模块名称
+const ModuleName = "ginapp"
+
+/**
+gin 自动配置模块：注册服务器配置、控制器注册器、默认的 gin.Engine 以及 GinServer
+*/
+type Module struct {
+	server *GinServer
+}
+
+func NewModule() *Module {
+	return &Module{}
+}
+
+func (m *Module) Name() string {
+	return ModuleName
+}
+
+func (m *Module) DefaultProperties() map[string]string {
+	return map[string]string{
+		"server.port": "8088",
+	}
+}
+
+func (m *Module) Register(app *sparrow.Application) error {
+	// 服务器配置
+	app.RegisterPropertiesBean(&ServerProperties{}, "", "server.", true)
+
+	// 控制器注册
+	app.RegisterBean(&GinRegistry{}, "gin_GinRegistry", true)
+
+	// 默认的 gin.Engine，用户注册了自己的 *gin.Engine 的话使用用户的
+	app.RegisterBean(gin.New(), "gin_Engine", true, sparrow.OnMissingBean((*gin.Engine)(nil)))
+
+	m.server = &GinServer{}
+	app.RegisterBean(m.server, "", true)
+	return nil
+}
+
+/**
+启动 GinServer，可以直接作为 sparrow.ContextRunner 使用
+*/
+func (m *Module) Run(ctx context.Context, app *sparrow.Application) (err error) {
+	if m.server == nil {
+		return errors.New("模块[" + ModuleName + "]未注册")
+	}
+	return m.server.RunContext(ctx, app)
+}
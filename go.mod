@@ -1,6 +1,6 @@
 module github.com/xkgo/sparrow
 
-go 1.17
+go 1.18
 
 require (
 	github.com/gin-gonic/gin v1.7.1
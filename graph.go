@@ -0,0 +1,258 @@
+package sparrow
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/xkgo/sparrow/annotations"
+	"github.com/xkgo/sparrow/logger"
+	"github.com/xkgo/sparrow/util/ConvertUtils"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 依赖注入方式
+const (
+	InjectKindField   = "field"   // @Inject 单个字段注入
+	InjectKindSlice   = "slice"   // @Inject Slice 注入
+	InjectKindMap     = "map"     // @Inject Map 注入
+	InjectKindLazy    = "lazy"    // @Inject:"lazy=true" 延迟获取
+	InjectKindFactory = "factory" // 工厂方法参数
+	InjectKindInit    = "init"    // Init 方法参数
+)
+
+/**
+依赖图节点
+*/
+type DependencyNode struct {
+	Name         string        `json:"name"`                // bean 名称
+	Type         string        `json:"type"`                // 类型
+	Primary      bool          `json:"primary"`             // 是否是主bean
+	Order        int64         `json:"order"`               // 排序
+	Scope        string        `json:"scope"`               // 作用域
+	Lazy         bool          `json:"lazy"`                // 是否延迟初始化
+	KeyPrefix    string        `json:"keyPrefix,omitempty"` // 配置Bean的配置前缀
+	Properties   bool          `json:"properties"`          // 是否是配置Bean
+	InitIndex    int           `json:"initIndex"`           // 初始化顺序，从 0 开始，没有初始化的话为 -1
+	InitDuration time.Duration `json:"initDuration"`        // Init 方法执行耗时
+}
+
+/**
+依赖图的边，From 依赖 To
+*/
+type DependencyEdge struct {
+	From       string   `json:"from"`                 // 依赖方 bean 名称
+	To         string   `json:"to"`                   // 被依赖的 bean 名称
+	Field      string   `json:"field"`                // 注入的字段名称，工厂方法参数为 arg0、arg1...，Init 方法参数为 Init.arg0...
+	Kind       string   `json:"kind"`                 // 注入方式，参考 InjectKindField 等
+	Parent     bool     `json:"parent,omitempty"`     // 被依赖的 Bean 是否在父容器中
+	Candidates []string `json:"candidates,omitempty"` // 按类型注入时的所有候选 Bean，有多个的时候选择 Primary
+}
+
+/**
+依赖图
+*/
+type DependencyGraph struct {
+	Nodes []*DependencyNode `json:"nodes"`
+	Edges []*DependencyEdge `json:"edges"`
+}
+
+/**
+计算当前容器的依赖图，按照 @Inject 以及工厂方法参数的解析规则计算每个依赖实际选择的 Bean
+*/
+func (a *Application) DependencyGraph() *DependencyGraph {
+	bds := a.beanDefinitions()
+	graph := &DependencyGraph{
+		Nodes: make([]*DependencyNode, 0, len(bds)),
+		Edges: make([]*DependencyEdge, 0),
+	}
+
+	a.lock.RLock()
+	initIndexes := make(map[*BeanDefinition]int)
+	for i, bd := range a.initializedBeans {
+		initIndexes[bd] = i
+	}
+	for _, bd := range bds {
+		initIndex, ok := initIndexes[bd]
+		if !ok {
+			initIndex = -1
+		}
+		graph.Nodes = append(graph.Nodes, &DependencyNode{
+			Name:         bd.Name,
+			Type:         bd.Type.String(),
+			Primary:      bd.Primary,
+			Order:        bd.Order,
+			Scope:        bd.Scope,
+			Lazy:         bd.Lazy,
+			KeyPrefix:    bd.KeyPrefix,
+			Properties:   bd.IsPropertiesBean,
+			InitIndex:    initIndex,
+			InitDuration: bd.InitDuration,
+		})
+	}
+	a.lock.RUnlock()
+
+	for _, bd := range bds {
+		graph.Edges = append(graph.Edges, a.dependencyEdgesOf(bd)...)
+	}
+	return graph
+}
+
+/**
+按照名称排序的 beanName，调用方需要持有容器锁
+*/
+func (a *Application) sortedBeanNames() []string {
+	names := make([]string, 0, len(a.container))
+	for name := range a.container {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (a *Application) dependencyEdgesOf(bd *BeanDefinition) (edges []*DependencyEdge) {
+	edges = make([]*DependencyEdge, 0)
+	addEdge := func(field, kind string, owner *Application, ref *BeanDefinition, candidates map[string]*BeanDefinition) {
+		edge := &DependencyEdge{From: bd.Name, To: ref.Name, Field: field, Kind: kind, Parent: owner != a}
+		if len(candidates) > 1 {
+			for name := range candidates {
+				edge.Candidates = append(edge.Candidates, name)
+			}
+			sort.Strings(edge.Candidates)
+		}
+		edges = append(edges, edge)
+	}
+
+	if bd.Factory.IsValid() {
+		ft := bd.Factory.Type()
+		for i := 0; i < ft.NumIn(); i++ {
+			if owner, ref, err := a.lookupPrimaryBeanDefinitionOfType(ft.In(i), ""); err == nil {
+				addEdge("arg"+strconv.Itoa(i), InjectKindFactory, owner, ref, owner.getBeanDefinitionsOfType(ft.In(i)))
+			}
+		}
+	}
+
+	bt := bd.Type
+	if bt.Kind() == reflect.Ptr {
+		bt = bt.Elem()
+	}
+	for i := 0; bt.Kind() == reflect.Struct && i < bt.NumField(); i++ {
+		tf := bt.Field(i)
+		inject, err := annotations.FindInject(tf.Tag)
+		if err != nil || inject == nil {
+			continue
+		}
+
+		if tf.Type.Kind() == reflect.Slice || tf.Type.Kind() == reflect.Map {
+			kind := InjectKindSlice
+			if tf.Type.Kind() == reflect.Map {
+				kind = InjectKindMap
+			}
+			bds := a.getQualifiedBeanDefinitionsOfType(tf.Type.Elem(), inject.Qualifier)
+			names := make([]string, 0, len(bds))
+			for name := range bds {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				addEdge(tf.Name, kind, a, bds[name], nil)
+			}
+			continue
+		}
+
+		beanType, kind := tf.Type, InjectKindField
+		if inject.Lazy {
+			if beanType.Kind() != reflect.Func || beanType.NumOut() < 1 {
+				continue
+			}
+			beanType, kind = beanType.Out(0), InjectKindLazy
+		}
+		owner, ref := a.resolveInjectBeanDefinition(beanType, inject)
+		if ref == nil {
+			continue
+		}
+		var candidates map[string]*BeanDefinition
+		if len(inject.Name) < 1 {
+			candidates = owner.getQualifiedBeanDefinitionsOfType(beanType, inject.Qualifier)
+		}
+		addEdge(tf.Name, kind, owner, ref, candidates)
+	}
+
+	// Init 方法参数，context.Context、env.Environment、*Application 除外
+	if method, ok := bd.Type.MethodByName("Init"); ok {
+		for i := 1; i < method.Type.NumIn(); i++ {
+			argType := method.Type.In(i)
+			if _, builtin := a.lifecycleBuiltinArg(nil, argType); builtin {
+				continue
+			}
+			if owner, ref, err := a.lookupPrimaryBeanDefinitionOfType(argType, ""); err == nil {
+				addEdge("Init.arg"+strconv.Itoa(i-1), InjectKindInit, owner, ref, owner.getBeanDefinitionsOfType(argType))
+			}
+		}
+	}
+	return
+}
+
+/**
+导出为 Graphviz DOT 格式，Primary Bean 加粗，延迟获取的依赖使用虚线，父容器中的 Bean 使用灰色
+*/
+func (g *DependencyGraph) ToDot() string {
+	builder := &strings.Builder{}
+	builder.WriteString("digraph sparrow {\n")
+	builder.WriteString("  rankdir=LR;\n")
+	builder.WriteString("  node [shape=box];\n")
+	for _, node := range g.Nodes {
+		label := node.Name + "\n" + node.Type
+		if node.Properties {
+			label += "\nprefix: " + node.KeyPrefix
+		}
+		attrs := "label=" + strconv.Quote(label)
+		if node.Primary {
+			attrs += ", style=bold"
+		}
+		builder.WriteString("  " + strconv.Quote(node.Name) + " [" + attrs + "];\n")
+	}
+	for _, edge := range g.Edges {
+		attrs := "label=" + strconv.Quote(edge.Field+"("+edge.Kind+")")
+		if edge.Kind == InjectKindLazy {
+			attrs += ", style=dashed"
+		}
+		if edge.Parent {
+			attrs += ", color=gray"
+		}
+		builder.WriteString("  " + strconv.Quote(edge.From) + " -> " + strconv.Quote(edge.To) + " [" + attrs + "];\n")
+	}
+	builder.WriteString("}\n")
+	return builder.String()
+}
+
+/**
+导出为 JSON 格式
+*/
+func (g *DependencyGraph) ToJSON() ([]byte, error) {
+	return json.MarshalIndent(g, "", "  ")
+}
+
+/**
+打印启动日志：按照初始化顺序输出 Bean 以及 Init 耗时，通过 sparrow.startup.log=true 开启
+*/
+func (a *Application) logStartupReport() {
+	if a.Environment == nil {
+		return
+	}
+	if !ConvertUtils.ToBoolWithDef(a.Environment.GetPropertyWithDef(PropertyKeyStartupLog, "false"), false) {
+		return
+	}
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	lines := make([]string, 0, len(a.initializedBeans))
+	var total time.Duration
+	for i, bd := range a.initializedBeans {
+		total += bd.InitDuration
+		lines = append(lines, fmt.Sprintf("%4d. %s(%s) %v", i+1, bd.Name, bd.Type.String(), bd.InitDuration))
+	}
+	logger.Info("Bean 初始化顺序（共", len(lines), "个，Init 总耗时 ", total, "）：\n", strings.Join(lines, "\n"))
+}
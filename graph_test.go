@@ -0,0 +1,44 @@
+package sparrow
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestApplication_DependencyGraph(t *testing.T) {
+	a := newTestApplication()
+	a.RegisterFactory(func() *OrderRepo {
+		return &OrderRepo{table: "t_order"}
+	}, "orderRepo", true)
+	a.RegisterFactory(NewOrderService, "", true)
+	a.RegisterBean(&MysqlDataSource{url: "master"}, "masterDataSource", true, WithQualifiers("primary"))
+	a.RegisterBean(&MysqlDataSource{url: "slave"}, "slaveDataSource", false, WithQualifiers("replica"))
+	a.RegisterBean(&DataSourceRouter{}, "", true)
+	assert.Nil(t, a.init())
+
+	graph := a.DependencyGraph()
+	assert.Len(t, graph.Nodes, 5)
+	assert.Equal(t, "DataSourceRouter", graph.Nodes[0].Name)
+	assert.True(t, graph.Nodes[0].InitIndex >= 0)
+
+	edges := make(map[string]*DependencyEdge)
+	for _, edge := range graph.Edges {
+		edges[edge.From+"."+edge.Field+"->"+edge.To] = edge
+	}
+	assert.Equal(t, InjectKindFactory, edges["OrderService.arg0->orderRepo"].Kind)
+	assert.Equal(t, InjectKindField, edges["DataSourceRouter.Primary->masterDataSource"].Kind)
+	assert.Equal(t, InjectKindMap, edges["DataSourceRouter.DataSources->slaveDataSource"].Kind)
+	assert.Equal(t, InjectKindSlice, edges["DataSourceRouter.Replicas->slaveDataSource"].Kind)
+	assert.Len(t, graph.Edges, 6)
+
+	dot := graph.ToDot()
+	assert.Contains(t, dot, `"OrderService" -> "orderRepo" [label="arg0(factory)"];`)
+	assert.Contains(t, dot, `"masterDataSource" [label="masterDataSource\n*sparrow.MysqlDataSource", style=bold];`)
+
+	data, err := graph.ToJSON()
+	assert.Nil(t, err)
+	parsed := &DependencyGraph{}
+	assert.Nil(t, json.Unmarshal(data, parsed))
+	assert.Equal(t, graph.Edges, parsed.Edges)
+}
@@ -0,0 +1,60 @@
+package sparrow
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var searchClientCreated int
+
+type SearchClient struct {
+	endpoint string
+}
+
+func (c *SearchClient) Init() {
+	searchClientCreated++
+}
+
+type SearchCommand struct {
+	client       func() *SearchClient             `@Inject:"required=true,lazy=true"`
+	repoProvider func() (*OrderRepo, error)       `@Inject:"name=orderRepo,lazy=true"`
+	missing      func() (*MysqlDataSource, error) `@Inject:"lazy=true"`
+}
+
+func TestApplication_LazyBean(t *testing.T) {
+	searchClientCreated = 0
+	a := newTestApplication()
+	a.RegisterBean(&SearchClient{endpoint: "http://search"}, "", true, WithLazy())
+	a.RegisterFactory(func() (*OrderRepo, error) {
+		return nil, errors.New("db unavailable")
+	}, "orderRepo", true, WithLazy())
+	a.RegisterBean(&SearchCommand{}, "", true)
+
+	assert.Nil(t, a.init())
+	assert.Equal(t, 0, searchClientCreated)
+
+	command := a.GetBeanByName("SearchCommand").(*SearchCommand)
+	assert.Nil(t, command.missing)
+	client := command.client()
+	assert.Equal(t, "http://search", client.endpoint)
+	assert.Same(t, client, command.client())
+	assert.Same(t, client, a.GetBeanByName("SearchClient"))
+	assert.Equal(t, 1, searchClientCreated)
+
+	repo, err := command.repoProvider()
+	assert.Nil(t, repo)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "db unavailable")
+}
+
+func TestApplication_LazyBeanByName(t *testing.T) {
+	searchClientCreated = 0
+	a := newTestApplication()
+	a.RegisterBean(&SearchClient{}, "searchClient", true, WithLazy())
+
+	assert.Nil(t, a.init())
+	assert.Equal(t, 0, searchClientCreated)
+	assert.NotNil(t, a.GetBeanByName("searchClient"))
+	assert.Equal(t, 1, searchClientCreated)
+}
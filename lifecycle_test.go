@@ -0,0 +1,117 @@
+package sparrow
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/xkgo/sparrow/env"
+	"testing"
+	"time"
+)
+
+var errMigrationFailed = errors.New("migration failed")
+
+type SchemaMigrator struct {
+	fail     bool
+	deadline time.Time
+	repo     *OrderRepo
+	closed   bool
+}
+
+func (m *SchemaMigrator) Init(ctx context.Context, repo *OrderRepo, environment env.Environment) error {
+	m.deadline, _ = ctx.Deadline()
+	m.repo = repo
+	if m.fail {
+		return errMigrationFailed
+	}
+	return nil
+}
+
+func (m *SchemaMigrator) Destroy(ctx context.Context) error {
+	if _, ok := ctx.Deadline(); !ok {
+		return errors.New("no shutdown deadline")
+	}
+	m.closed = true
+	return errors.New("close failed")
+}
+
+func TestApplication_InitWithParams(t *testing.T) {
+	a := newTestApplication()
+	a.Environment.GetPropertySources().AddFirst(env.NewMapPropertySource("startup", map[string]string{PropertyKeyStartupTimeout: "5s"}))
+	a.RegisterBean(&SchemaMigrator{}, "", true)
+	a.RegisterBean(&OrderRepo{table: "t_order"}, "orderRepo", true)
+
+	start := time.Now()
+	assert.Nil(t, a.init())
+
+	migrator := a.GetBeanByName("SchemaMigrator").(*SchemaMigrator)
+	assert.Same(t, a.GetBeanByName("orderRepo"), migrator.repo)
+	assert.WithinDuration(t, start.Add(5*time.Second), migrator.deadline, time.Second)
+	assert.Equal(t, "orderRepo", a.initializedBeans[0].Name)
+
+	edges := a.DependencyGraph().Edges
+	assert.Len(t, edges, 1)
+	assert.Equal(t, InjectKindInit, edges[0].Kind)
+	assert.Equal(t, "Init.arg1", edges[0].Field)
+
+	err := a.destroyBeans()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "close failed")
+	assert.True(t, migrator.closed)
+}
+
+func TestApplication_InitError(t *testing.T) {
+	a := NewApplication()
+	a.RegisterBean(&OrderRepo{table: "t_order"}, "orderRepo", true)
+	a.RegisterBean(&SchemaMigrator{fail: true}, "", true)
+
+	err := a.Run(env.New(env.ConfigDirs("./testdata")))
+	assert.NotNil(t, err)
+	assert.True(t, errors.Is(err, errMigrationFailed))
+	assert.Contains(t, err.Error(), "SchemaMigrator")
+}
+
+type ReportJob struct {
+}
+
+func (j *ReportJob) Init(job *ReportJob) {
+}
+
+func TestApplication_InitParamCycle(t *testing.T) {
+	a := newTestApplication()
+	a.RegisterBean(&ReportJob{}, "", true)
+
+	err := a.init()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "ReportJob.Init.arg0 -> ReportJob")
+}
+
+func TestApplication_ParallelInitWithParams(t *testing.T) {
+	a := newTestApplication()
+	WithParallelInit(4)(a)
+	a.RegisterBean(&SchemaMigrator{}, "", true)
+	a.RegisterBean(&OrderRepo{table: "t_order"}, "orderRepo", true)
+
+	assert.Nil(t, a.init())
+	migrator := a.GetBeanByName("SchemaMigrator").(*SchemaMigrator)
+	assert.Same(t, a.GetBeanByName("orderRepo"), migrator.repo)
+	assert.False(t, migrator.deadline.IsZero())
+}
+
+func TestApplication_TimeoutProperty(t *testing.T) {
+	a := newTestApplication()
+	a.Environment.GetPropertySources().AddFirst(env.NewMapPropertySource("timeout", map[string]string{
+		PropertyKeyStartupTimeout: "90", PropertyKeyShutdownTimeout: "0",
+	}))
+
+	assert.Equal(t, 90*time.Second, a.getStartupTimeout())
+	// 小于等于 0 的配置使用默认值
+	assert.Equal(t, DefaultShutdownTimeout, a.getShutdownTimeout())
+
+	b := newTestApplication()
+	b.Environment.GetPropertySources().AddFirst(env.NewMapPropertySource("timeout", map[string]string{
+		PropertyKeyStartupTimeout: "-5", PropertyKeyShutdownTimeout: "-1s",
+	}))
+	assert.Equal(t, DefaultStartupTimeout, b.getStartupTimeout())
+	assert.Equal(t, DefaultShutdownTimeout, b.getShutdownTimeout())
+}
@@ -0,0 +1,143 @@
+package sparrow
+
+import (
+	"errors"
+	"github.com/xkgo/sparrow/env"
+	"github.com/xkgo/sparrow/logger"
+	"github.com/xkgo/sparrow/util"
+	"sort"
+	"strings"
+)
+
+/**
+自动配置模块，用于组合 ginapp、日志、调度以及内部的各种 starter，每个模块可以提供默认配置以及注册 Bean
+可选实现：
+1. util.Ordered 模块排序，越小越先注册
+2. ModuleDependency 依赖的其他模块，被依赖的模块会先注册
+*/
+type Module interface {
+	/**
+	模块名称，唯一
+	*/
+	Name() string
+
+	/**
+	模块默认配置，优先级最低，可以被配置文件、命令行等覆盖
+	*/
+	DefaultProperties() map[string]string
+
+	/**
+	注册模块的 Bean
+	*/
+	Register(app *Application) error
+}
+
+/**
+模块依赖
+*/
+type ModuleDependency interface {
+	/**
+	依赖的模块名称列表
+	*/
+	DependsOn() []string
+}
+
+// 模块默认配置来源名称前缀
+const ModulePropertySourceNamePrefix = "module:"
+
+/**
+添加自动配置模块
+*/
+func WithModules(modules ...Module) Option {
+	return func(app *Application) {
+		app.modules = append(app.modules, modules...)
+	}
+}
+
+/**
+按照排序以及依赖关系注册模块：添加模块默认配置，然后执行模块的 Bean 注册
+*/
+func (a *Application) registerModules() (err error) {
+	if len(a.modules) < 1 {
+		return nil
+	}
+	modules, err := sortModules(a.modules)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(modules))
+	for _, module := range modules {
+		properties := module.DefaultProperties()
+		if len(properties) > 0 {
+			a.Environment.GetPropertySources().AddLast(env.NewMapPropertySource(ModulePropertySourceNamePrefix+module.Name(), properties))
+		}
+		if err = module.Register(a); err != nil {
+			return errors.New("模块[" + module.Name() + "]注册失败: " + err.Error())
+		}
+		names = append(names, module.Name())
+	}
+	logger.Info("已加载模块: ", strings.Join(names, ", "))
+	return nil
+}
+
+/**
+模块排序：先按照 util.Ordered 排序，然后保证被依赖的模块排在前面
+*/
+func sortModules(modules []Module) (sorted []Module, err error) {
+	ordered := append(make([]Module, 0, len(modules)), modules...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return getModuleOrder(ordered[i]) < getModuleOrder(ordered[j])
+	})
+
+	moduleMap := make(map[string]Module)
+	for _, module := range ordered {
+		if _, ok := moduleMap[module.Name()]; ok {
+			return nil, errors.New("模块[" + module.Name() + "]重复添加")
+		}
+		moduleMap[module.Name()] = module
+	}
+
+	sorted = make([]Module, 0, len(ordered))
+	visited := make(map[string]bool) // true: 已完成, false: 处理中
+	var visit func(module Module, chain []string) error
+	visit = func(module Module, chain []string) error {
+		name := module.Name()
+		if done, ok := visited[name]; ok {
+			if !done {
+				return errors.New("模块存在循环依赖: " + strings.Join(append(chain, name), " -> "))
+			}
+			return nil
+		}
+		visited[name] = false
+		chain = append(chain, name)
+		if dependency, ok := module.(ModuleDependency); ok {
+			for _, dependsOn := range dependency.DependsOn() {
+				dependModule, ok := moduleMap[dependsOn]
+				if !ok {
+					return errors.New("模块[" + name + "]依赖的模块[" + dependsOn + "]不存在")
+				}
+				if err := visit(dependModule, chain); err != nil {
+					return err
+				}
+			}
+		}
+		visited[name] = true
+		sorted = append(sorted, module)
+		return nil
+	}
+
+	for _, module := range ordered {
+		if err = visit(module, nil); err != nil {
+			return nil, err
+		}
+	}
+	return sorted, nil
+}
+
+func getModuleOrder(module Module) int {
+	if o, ok := module.(util.Ordered); ok {
+		return o.GetOrder()
+	}
+	return 0
+}
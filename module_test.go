@@ -0,0 +1,66 @@
+package sparrow
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type testModule struct {
+	name       string
+	order      int
+	dependsOn  []string
+	properties map[string]string
+	registered *[]string
+}
+
+func (m *testModule) Name() string {
+	return m.name
+}
+
+func (m *testModule) DefaultProperties() map[string]string {
+	return m.properties
+}
+
+func (m *testModule) Register(app *Application) error {
+	*m.registered = append(*m.registered, m.name)
+	app.RegisterBean(&ConnPool{}, m.name+"Pool", false)
+	return nil
+}
+
+func (m *testModule) GetOrder() int {
+	return m.order
+}
+
+func (m *testModule) DependsOn() []string {
+	return m.dependsOn
+}
+
+func TestApplication_RegisterModules(t *testing.T) {
+	registered := make([]string, 0)
+	a := newTestApplication()
+	WithModules(
+		&testModule{name: "web", order: 1, dependsOn: []string{"logging"}, registered: &registered,
+			properties: map[string]string{"server.port": "8088", "table.name.user": "module"}},
+		&testModule{name: "scheduler", order: 2, registered: &registered},
+		&testModule{name: "logging", order: 3, registered: &registered},
+	)(a)
+
+	assert.Nil(t, a.registerModules())
+	assert.Equal(t, []string{"logging", "web", "scheduler"}, registered)
+	assert.NotNil(t, a.GetBeanByName("webPool"))
+
+	// 默认配置优先级最低
+	assert.Equal(t, "8088", a.Environment.GetPropertyWithDef("server.port", ""))
+	assert.Equal(t, "tb_user_info", a.Environment.GetPropertyWithDef("table.name.user", ""))
+}
+
+func TestApplication_RegisterModulesMissingDependency(t *testing.T) {
+	registered := make([]string, 0)
+	a := newTestApplication()
+	WithModules(&testModule{name: "web", dependsOn: []string{"logging"}, registered: &registered})(a)
+
+	err := a.registerModules()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "logging")
+	assert.Empty(t, registered)
+}
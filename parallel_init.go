@@ -0,0 +1,183 @@
+package sparrow
+
+import (
+	"errors"
+	"fmt"
+	"github.com/xkgo/sparrow/util/ReflectUtils"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+/**
+开启并行初始化：按照依赖关系计算 DAG，互不依赖的 Bean 的创建（工厂方法、Init 方法等）会并发执行，
+依赖的 Bean 一定会先完成初始化，可以同时开始的 Bean 按照 Order 从大到小启动
+@param workers 最多同时创建 Bean 的 Goroutine 数量，小于等于 0 的话使用 CPU 核数
+*/
+func WithParallelInit(workers int) Option {
+	return func(app *Application) {
+		if workers <= 0 {
+			workers = runtime.NumCPU()
+		}
+		app.initWorkers = workers
+	}
+}
+
+type parallelInitResult struct {
+	bd  *BeanDefinition
+	err error
+}
+
+/**
+并行初始化：依赖的 Bean 都初始化完成之后，在工作 Goroutine 中完成 Bean 的创建（工厂方法、注入、Init 以及 BeanPostProcessor），
+某个 Bean 初始化失败的话，依赖它的 Bean 会被跳过，其他 Bean 继续初始化，所有的失败按照 beanName 排序之后合并返回
+*/
+func (a *Application) parallelInjectProcess() (err error) {
+	pending := a.parallelInitDependencies()
+
+	results := make(chan *parallelInitResult, len(pending))
+	failed := make(map[*BeanDefinition]string)
+	running := 0
+	for {
+		for _, bd := range a.sortedParallelCandidates(pending) {
+			if running >= a.initWorkers {
+				break
+			}
+			if a.isReady(bd) {
+				// 被其他 Bean 的 Init 方法提前获取（初始化）了
+				delete(pending, bd)
+				continue
+			}
+			if dep := failedDependency(pending[bd], failed); dep != nil {
+				delete(pending, bd)
+				failed[bd] = "依赖的Bean[" + dep.Name + "]初始化失败，跳过初始化"
+				continue
+			}
+			if !a.dependenciesReady(pending[bd]) {
+				continue
+			}
+			delete(pending, bd)
+			running++
+			a.startParallelInit(bd, results)
+		}
+
+		if running == 0 {
+			break
+		}
+		// 等待任意一个 Bean 初始化完成
+		result := <-results
+		running--
+		if result.err != nil {
+			failed[result.bd] = result.err.Error()
+		}
+	}
+
+	if len(failed) > 0 {
+		return parallelInitError(failed)
+	}
+
+	// 依赖关系中存在循环，交给串行初始化处理（会报告完整的循环依赖链）
+	for _, bd := range a.sortedParallelCandidates(pending) {
+		if err = a.wireBean(bd, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/**
+计算需要初始化的 Bean 以及它们直接依赖的（同样需要初始化的）Bean，延迟获取的依赖、父容器中的 Bean 不算在内
+*/
+func (a *Application) parallelInitDependencies() (pending map[*BeanDefinition][]*BeanDefinition) {
+	pending = make(map[*BeanDefinition][]*BeanDefinition)
+	bds := make(map[string]*BeanDefinition)
+	for _, bd := range a.beanDefinitions() {
+		bds[bd.Name] = bd
+		if bd.IsSingleton() && !bd.Lazy && !a.isReady(bd) {
+			pending[bd] = make([]*BeanDefinition, 0)
+		}
+	}
+	for bd := range pending {
+		for _, edge := range a.dependencyEdgesOf(bd) {
+			if edge.Parent || edge.Kind == InjectKindLazy {
+				continue
+			}
+			if dep, ok := bds[edge.To]; ok && dep != bd {
+				if _, scheduled := pending[dep]; scheduled {
+					pending[bd] = append(pending[bd], dep)
+				}
+			}
+		}
+	}
+	return
+}
+
+/**
+按照 Order 从大到小（相同的话按照 beanName）排序，保证调度顺序是确定的
+*/
+func (a *Application) sortedParallelCandidates(pending map[*BeanDefinition][]*BeanDefinition) []*BeanDefinition {
+	candidates := make([]*BeanDefinition, 0, len(pending))
+	beans := make(map[*BeanDefinition]interface{})
+	a.lock.RLock()
+	for bd := range pending {
+		candidates = append(candidates, bd)
+		beans[bd] = bd.Bean
+	}
+	a.lock.RUnlock()
+
+	orders := make(map[*BeanDefinition]int64)
+	for bd, bean := range beans {
+		if bean != nil {
+			orders[bd], _ = ReflectUtils.GetRetInt64(bean, "GetOrder")
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		o1, o2 := orders[candidates[i]], orders[candidates[j]]
+		if o1 != o2 {
+			return o2 < o1
+		}
+		return candidates[i].Name < candidates[j].Name
+	})
+	return candidates
+}
+
+/**
+在工作 Goroutine 中创建 Bean，完成之后通过 results 通知，每个工作 Goroutine 绑定自己的 context
+*/
+func (a *Application) startParallelInit(bd *BeanDefinition, results chan *parallelInitResult) {
+	var err error
+	runGoroutine(func() {
+		err = a.wireBean(bd, nil)
+	}, func(r interface{}) {
+		err = fmt.Errorf("初始化发生panic: %v", r)
+	}, func() {
+		results <- &parallelInitResult{bd: bd, err: err}
+	})
+}
+
+func failedDependency(dependencies []*BeanDefinition, failed map[*BeanDefinition]string) *BeanDefinition {
+	for _, dep := range dependencies {
+		if _, ok := failed[dep]; ok {
+			return dep
+		}
+	}
+	return nil
+}
+
+func (a *Application) dependenciesReady(dependencies []*BeanDefinition) bool {
+	for _, dep := range dependencies {
+		if !a.isReady(dep) {
+			return false
+		}
+	}
+	return true
+}
+
+func parallelInitError(failed map[*BeanDefinition]string) error {
+	lines := make([]string, 0, len(failed))
+	for bd, msg := range failed {
+		lines = append(lines, "Bean["+bd.Name+"]: "+msg)
+	}
+	sort.Strings(lines)
+	return errors.New("并行初始化Bean失败：\n" + strings.Join(lines, "\n"))
+}
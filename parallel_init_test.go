@@ -0,0 +1,135 @@
+package sparrow
+
+import (
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+type initRecorder struct {
+	mu      sync.Mutex
+	started []string
+}
+
+func (r *initRecorder) record(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.started = append(r.started, name)
+}
+
+var parallelRecorder = &initRecorder{}
+
+/**
+所有参与方都到达之后才会放行，超时还没有全部到达的话返回 false
+*/
+type initBarrier struct {
+	wg sync.WaitGroup
+}
+
+func newInitBarrier(parties int) *initBarrier {
+	b := &initBarrier{}
+	b.wg.Add(parties)
+	return b
+}
+
+func (b *initBarrier) await() bool {
+	b.wg.Done()
+	done := make(chan struct{})
+	go func() {
+		b.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(5 * time.Second):
+		return false
+	}
+}
+
+type SlowBroker struct {
+	name       string
+	barrier    *initBarrier
+	overlapped bool
+}
+
+func (b *SlowBroker) Init() {
+	if b.barrier != nil {
+		b.overlapped = b.barrier.await()
+	}
+	parallelRecorder.record(b.name)
+}
+
+type BrokerGateway struct {
+	brokers []*SlowBroker `@Inject:"required=true"`
+	ready   int
+}
+
+func (g *BrokerGateway) Init() {
+	for _, broker := range g.brokers {
+		if broker.name != "" {
+			g.ready++
+		}
+	}
+	parallelRecorder.record("gateway")
+}
+
+type FailingBroker struct {
+}
+
+func (b *FailingBroker) Init() {
+	panic("broker unreachable")
+}
+
+type FailingBrokerClient struct {
+	broker *FailingBroker `@Inject:"required=true"`
+}
+
+func TestApplication_ParallelInit(t *testing.T) {
+	parallelRecorder = &initRecorder{}
+	a := newTestApplication()
+	WithParallelInit(4)(a)
+	// 3 个 Init 方法以及 1 个工厂方法同时执行才能全部通过
+	barrier := newInitBarrier(4)
+	brokers := make([]*SlowBroker, 0)
+	for _, name := range []string{"broker1", "broker2", "broker3"} {
+		broker := &SlowBroker{name: name, barrier: barrier}
+		brokers = append(brokers, broker)
+		a.RegisterBean(broker, name, false)
+	}
+	factoryOverlapped := false
+	a.RegisterFactory(func() *SlowBroker {
+		factoryOverlapped = barrier.await()
+		return &SlowBroker{name: "broker4"}
+	}, "broker4", false)
+	a.RegisterBean(&BrokerGateway{}, "", true)
+
+	assert.Nil(t, a.init())
+	for _, broker := range brokers {
+		assert.True(t, broker.overlapped, broker.name)
+	}
+	assert.True(t, factoryOverlapped)
+
+	gateway := a.GetBeanByName("BrokerGateway").(*BrokerGateway)
+	assert.Equal(t, 4, gateway.ready)
+	assert.Len(t, parallelRecorder.started, 5)
+	assert.Equal(t, "gateway", parallelRecorder.started[4])
+	assert.Equal(t, "BrokerGateway", a.initializedBeans[4].Name)
+}
+
+func TestApplication_ParallelInitError(t *testing.T) {
+	parallelRecorder = &initRecorder{}
+	a := newTestApplication()
+	WithParallelInit(2)(a)
+	a.RegisterBean(&FailingBroker{}, "failingBroker", true)
+	a.RegisterBean(&FailingBrokerClient{}, "failingClient", true)
+	a.RegisterBean(&SlowBroker{name: "broker1"}, "broker1", true)
+
+	err := a.init()
+	assert.NotNil(t, err)
+	assert.Equal(t, "并行初始化Bean失败：\n"+
+		"Bean[failingBroker]: 初始化发生panic: broker unreachable\n"+
+		"Bean[failingClient]: 依赖的Bean[failingBroker]初始化失败，跳过初始化", err.Error())
+	assert.Equal(t, []string{"broker1"}, parallelRecorder.started)
+}
@@ -0,0 +1,190 @@
+package sparrow
+
+import (
+	"errors"
+	"strings"
+)
+
+/**
+profile 表达式，参数为当前激活的 profile 集合
+*/
+type profileMatcher func(active map[string]bool) bool
+
+/**
+任意一个 profile 表达式满足时注册，在容器初始化时根据 Environment.GetActiveProfiles() 进行判断，表达式支持：
+1. dev：dev 激活
+2. !prod：prod 没有激活
+3. dev & wuxi：dev 和 wuxi 同时激活
+4. dev | test：dev 或者 test 激活
+5. 括号分组，如：(dev | test) & !wuxi，& 的优先级高于 |
+表达式非法的话会 panic，如：
+
+	RegisterBean(&MockPayment{}, "", true, sparrow.ForProfiles("dev", "test"))
+*/
+func ForProfiles(expressions ...string) BeanOption {
+	return profileCondition("ForProfiles", expressions)
+}
+
+/**
+创建 profile 条件，ForProfiles、OnProfile 共用，name 用于错误信息以及条件描述
+*/
+func profileCondition(name string, expressions []string) BeanOption {
+	if len(expressions) < 1 {
+		panic(errors.New(name + " 至少需要一个 profile 表达式"))
+	}
+	matchers := make([]profileMatcher, 0, len(expressions))
+	for _, expression := range expressions {
+		matcher, err := parseProfileExpression(expression)
+		if err != nil {
+			panic(err)
+		}
+		matchers = append(matchers, matcher)
+	}
+	return WithConditions(&condition{
+		description: name + "(" + strings.Join(expressions, ",") + ")",
+		matches: func(app *Application, bd *BeanDefinition) bool {
+			active := make(map[string]bool)
+			for _, profile := range app.Environment.GetActiveProfiles() {
+				active[profile] = true
+			}
+			for _, matcher := range matchers {
+				if matcher(active) {
+					return true
+				}
+			}
+			return false
+		},
+	})
+}
+
+/**
+解析 profile 表达式，语法：
+
+	expr   = and { "|" and }
+	and    = unary { "&" unary }
+	unary  = "!" unary | "(" expr ")" | profile
+*/
+func parseProfileExpression(expression string) (matcher profileMatcher, err error) {
+	parser := &profileParser{expression: expression, tokens: tokenizeProfileExpression(expression)}
+	if len(parser.tokens) < 1 {
+		return nil, errors.New("profile 表达式不能为空")
+	}
+	matcher, err = parser.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if parser.pos < len(parser.tokens) {
+		return nil, parser.error("多余的 '" + parser.tokens[parser.pos] + "'")
+	}
+	return matcher, nil
+}
+
+func tokenizeProfileExpression(expression string) []string {
+	tokens := make([]string, 0)
+	start := -1
+	for i, c := range expression {
+		isOperator := strings.ContainsRune("!&|()", c)
+		if isOperator || c == ' ' || c == '\t' {
+			if start >= 0 {
+				tokens = append(tokens, expression[start:i])
+				start = -1
+			}
+			if isOperator {
+				tokens = append(tokens, string(c))
+			}
+		} else if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, expression[start:])
+	}
+	return tokens
+}
+
+type profileParser struct {
+	expression string
+	tokens     []string
+	pos        int
+}
+
+func (p *profileParser) error(msg string) error {
+	return errors.New("非法的 profile 表达式[" + p.expression + "]: " + msg)
+}
+
+func (p *profileParser) accept(token string) bool {
+	if p.pos < len(p.tokens) && p.tokens[p.pos] == token {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *profileParser) parseOr() (profileMatcher, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("|") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(active map[string]bool) bool {
+			return l(active) || right(active)
+		}
+	}
+	return left, nil
+}
+
+func (p *profileParser) parseAnd() (profileMatcher, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(active map[string]bool) bool {
+			return l(active) && right(active)
+		}
+	}
+	return left, nil
+}
+
+func (p *profileParser) parseUnary() (profileMatcher, error) {
+	if p.pos >= len(p.tokens) {
+		return nil, p.error("缺少 profile")
+	}
+	if p.accept("!") {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return func(active map[string]bool) bool {
+			return !operand(active)
+		}, nil
+	}
+	if p.accept("(") {
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, p.error("缺少 ')'")
+		}
+		return inner, nil
+	}
+	token := p.tokens[p.pos]
+	if strings.ContainsAny(token, "&|)") {
+		return nil, p.error("'" + token + "' 之前缺少 profile")
+	}
+	p.pos++
+	return func(active map[string]bool) bool {
+		return active[token]
+	}, nil
+}
@@ -0,0 +1,88 @@
+package sparrow
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/xkgo/sparrow/env"
+	"testing"
+)
+
+func TestParseProfileExpression(t *testing.T) {
+	active := map[string]bool{"dev": true, "wuxi": true}
+	cases := map[string]bool{
+		"dev":                     true,
+		"prod":                    false,
+		"!prod":                   true,
+		"!!dev":                   true,
+		"dev & wuxi":              true,
+		"dev&prod":                false,
+		"prod | wuxi":             true,
+		"prod | test & dev":       false,
+		"(prod | test) | dev":     true,
+		"(dev | test) & !wuxi":    false,
+		"!(prod & dev) & wuxi":    true,
+		"  dev   &   !test   ":    true,
+		"dev & (wuxi | (prod))":   true,
+		"!dev | !wuxi | !(dev)":   false,
+		"(dev & wuxi) | prod":     true,
+		"dao-dev | dev.local":     false,
+		"dev & wuxi & !prod | no": true,
+	}
+	for expression, expected := range cases {
+		matcher, err := parseProfileExpression(expression)
+		assert.Nil(t, err, expression)
+		assert.Equal(t, expected, matcher(active), expression)
+	}
+
+	for _, expression := range []string{"", "  ", "dev &", "& dev", "(dev", "dev)", "dev wuxi", "!", "dev | | test", "()"} {
+		_, err := parseProfileExpression(expression)
+		assert.NotNil(t, err, expression)
+	}
+}
+
+type PaymentGateway interface {
+	Pay(amount int) string
+}
+
+type MockPayment struct {
+}
+
+func (p *MockPayment) Pay(amount int) string {
+	return "mock"
+}
+
+type WuxiPayment struct {
+}
+
+func (p *WuxiPayment) Pay(amount int) string {
+	return "wuxi"
+}
+
+func TestApplication_ForProfiles(t *testing.T) {
+	a := NewApplication()
+	a.Environment = env.New(env.ConfigDirs("./testdata"), env.AppendCommandLine("--sparrow.profile.include=dev,wuxi"))
+
+	a.RegisterBean(&MockPayment{}, "mockPayment", false, ForProfiles("test", "dev & !wuxi"))
+	a.RegisterBean(&WuxiPayment{}, "wuxiPayment", false, ForProfiles("dev & wuxi"))
+	a.RegisterBean(&ConnPool{}, "nonProdPool", false, ForProfiles("!prod"))
+	// OnProfile 和 ForProfiles 使用相同的表达式
+	a.RegisterBean(&ConnPool{}, "wuxiPool", false, OnProfile("wuxi & !prod"))
+	a.RegisterBean(&ConnPool{}, "prodPool", false, OnProfile("prod"))
+
+	assert.Nil(t, a.init())
+	assert.Nil(t, a.GetBeanByName("mockPayment"))
+	assert.NotNil(t, a.GetBeanByName("wuxiPayment"))
+	assert.NotNil(t, a.GetBeanByName("nonProdPool"))
+	assert.NotNil(t, a.GetBeanByName("wuxiPool"))
+	assert.Nil(t, a.GetBeanByName("prodPool"))
+
+	bean, err := a.GetBeanByType((*PaymentGateway)(nil))
+	assert.Nil(t, err)
+	assert.Equal(t, "wuxi", (*bean.(*PaymentGateway)).Pay(1))
+
+	assert.Panics(t, func() {
+		ForProfiles("dev &")
+	})
+	assert.Panics(t, func() {
+		OnProfile()
+	})
+}
@@ -0,0 +1,28 @@
+package sparrow
+
+const (
+	ScopeSingleton = "singleton" // 单例，整个容器只有一个实例，默认作用域
+	ScopePrototype = "prototype" // 原型，每次获取、注入的时候都会创建一个新的完整注入过的实例
+)
+
+/**
+Bean 作用域，用于扩展自定义的作用域，比如 request、tenant 等
+*/
+type Scope interface {
+	/**
+	获取作用域中的 Bean 实例
+	@param bd Bean 定义
+	@param creator 创建一个新的实例（已经完成属性注入并执行了初始化方法），作用域中不存在的话通过这个创建
+	*/
+	Get(bd *BeanDefinition, creator func() (bean interface{}, err error)) (bean interface{}, err error)
+}
+
+/**
+原型作用域，每次都创建新的实例
+*/
+type prototypeScope struct {
+}
+
+func (p *prototypeScope) Get(bd *BeanDefinition, creator func() (bean interface{}, err error)) (bean interface{}, err error) {
+	return creator()
+}
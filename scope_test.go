@@ -0,0 +1,86 @@
+package sparrow
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type Accumulator struct {
+	name  string
+	total int
+	pool  *ConnPool `@Inject:"required=true"`
+}
+
+func (a *Accumulator) Init() {
+	a.total = 100
+}
+
+type JobA struct {
+	acc *Accumulator `@Inject:"required=true"`
+}
+
+type JobB struct {
+	acc *Accumulator `@Inject:"required=true"`
+}
+
+type fixedScope struct {
+	beans map[string]interface{}
+}
+
+func (f *fixedScope) Get(bd *BeanDefinition, creator func() (bean interface{}, err error)) (bean interface{}, err error) {
+	if bean, ok := f.beans[bd.Name]; ok {
+		return bean, nil
+	}
+	bean, err = creator()
+	if err == nil {
+		f.beans[bd.Name] = bean
+	}
+	return
+}
+
+func TestApplication_PrototypeScope(t *testing.T) {
+	a := newTestApplication()
+	a.RegisterBean(&ConnPool{}, "", true)
+	a.RegisterBean(&Accumulator{name: "acc"}, "", true, WithScope(ScopePrototype))
+	a.RegisterBean(&JobA{}, "", true)
+	a.RegisterBean(&JobB{}, "", true)
+
+	assert.Nil(t, a.init())
+
+	accA := a.GetBeanByName("JobA").(*JobA).acc
+	accB := a.GetBeanByName("JobB").(*JobB).acc
+	assert.NotSame(t, accA, accB)
+	assert.Equal(t, "acc", accA.name)
+	assert.Equal(t, 100, accA.total)
+	assert.Same(t, a.GetBeanByName("ConnPool"), accA.pool)
+
+	acc1 := a.GetBeanByName("Accumulator").(*Accumulator)
+	acc2 := a.GetBeanByName("Accumulator").(*Accumulator)
+	assert.NotSame(t, acc1, acc2)
+
+	// 模板对象不会被注入
+	assert.Nil(t, a.container["Accumulator"].Bean.(*Accumulator).pool)
+}
+
+func TestApplication_RegisterScope(t *testing.T) {
+	a := newTestApplication()
+	a.RegisterScope("tenant", &fixedScope{beans: make(map[string]interface{})})
+	a.RegisterBean(&ConnPool{}, "", true)
+	a.RegisterFactory(func() *Accumulator {
+		return &Accumulator{name: "factory"}
+	}, "", true, WithScope("tenant"))
+	a.RegisterBean(&JobA{}, "", true)
+	a.RegisterBean(&JobB{}, "", true)
+
+	assert.Nil(t, a.init())
+
+	accA := a.GetBeanByName("JobA").(*JobA).acc
+	accB := a.GetBeanByName("JobB").(*JobB).acc
+	assert.Same(t, accA, accB)
+	assert.Equal(t, "factory", accA.name)
+	assert.NotNil(t, accA.pool)
+
+	assert.Panics(t, func() {
+		a.RegisterScope(ScopePrototype, &fixedScope{})
+	})
+}
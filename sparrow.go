@@ -11,9 +11,10 @@ import (
 @param beanPtr 要注册的bean指针对象
 @param beanName bean的名称
 @param primary 是否是主bean
+@param options Bean 选项，如：WithScope(ScopePrototype)
 */
-func RegisterBean(beanPtr interface{}, beanName string, primary bool) {
-	getApp().RegisterBean(beanPtr, beanName, primary)
+func RegisterBean(beanPtr interface{}, beanName string, primary bool, options ...BeanOption) {
+	getApp().RegisterBean(beanPtr, beanName, primary, options...)
 }
 
 /**
@@ -23,16 +24,46 @@ func RegisterBean(beanPtr interface{}, beanName string, primary bool) {
 @param keyPrefix key前缀，会直接拼接，如果注意有必要的话需要加 .
 @param primary 是否是主bean
 */
-func RegisterPropertiesBean(beanPtr interface{}, beanName string, keyPrefix string, primary bool) {
-	getApp().RegisterPropertiesBean(beanPtr, beanName, keyPrefix, primary)
+func RegisterPropertiesBean(beanPtr interface{}, beanName string, keyPrefix string, primary bool, options ...BeanOption) {
+	getApp().RegisterPropertiesBean(beanPtr, beanName, keyPrefix, primary, options...)
 }
 
-func RegisterPropertiesBeanListen(beanPtr interface{}, beanName string, keyPrefix string, changedListen, primary bool) {
-	getApp().RegisterPropertiesBeanListen(beanPtr, beanName, keyPrefix, changedListen, primary)
+/**
+注册 PropertiesBean，并且可以监听配置的变化，
+配置变更时会整体更新 beanPtr，beanPtr 实现了 sync.Locker（如：嵌入 sync.RWMutex）的话会加锁更新，读取方加读锁读取即可，
+没有实现 sync.Locker 的话并发读取可能读到部分更新的配置，也可以实现 env.PropertiesChangedListener 获取变更之后的配置
+@param changedListen 是否需要监听配置的变化
+*/
+func RegisterPropertiesBeanListen(beanPtr interface{}, beanName string, keyPrefix string, changedListen, primary bool, options ...BeanOption) {
+	getApp().RegisterPropertiesBeanListen(beanPtr, beanName, keyPrefix, changedListen, primary, options...)
+}
+
+/**
+通过工厂方法注册Bean，工厂方法参数会按照类型从容器中解析注入，工厂方法返回的 error 会导致 Run 失败
+@param fn 工厂方法，格式：func(deps...) *T 或者 func(deps...) (*T, error)
+@param beanName bean的名称，为空则使用 T 的类型名称
+@param primary 是否是主bean
+*/
+func RegisterFactory(fn interface{}, beanName string, primary bool, options ...BeanOption) {
+	getApp().RegisterFactory(fn, beanName, primary, options...)
 }
 
 /**
-获取指定名称的Bean，不存在则返回 nil
+注册自定义作用域，比如 request、tenant 作用域，注册 Bean 的时候通过 WithScope(name) 使用
+*/
+func RegisterScope(name string, scope Scope) {
+	getApp().RegisterScope(name, scope)
+}
+
+/**
+注册别名，别名可以指向 beanName 或者另一个别名，用于 Bean 改名之后兼容旧名称
+*/
+func RegisterAlias(alias, beanName string) {
+	getApp().RegisterAlias(alias, beanName)
+}
+
+/**
+获取指定名称（或者别名）的Bean，不存在则返回 nil
 */
 func GetBeanByName(beanName string) (beanPtr interface{}) {
 	return getApp().GetBeanByName(beanName)
@@ -50,7 +81,7 @@ func GetBeanByType(beanTemplate interface{}) (beanPtr interface{}, err error) {
 }
 
 /**
-获取所有的 BeanNames
+获取所有的 BeanNames，包括别名
 */
 func GetBeanNames() []string {
 	return getApp().GetBeanNames()
@@ -69,6 +100,20 @@ func AppendBeforeInitHandler(handler Runner) {
 	getApp().AppendBeforeInitHandler(handler)
 }
 
+/**
+发布事件，实现了 OnEvent(event T) 方法的 Bean 以及 AddEventListener 添加的监听器会按照参数类型接收事件
+*/
+func Publish(event interface{}) {
+	getApp().Publish(event)
+}
+
+/**
+添加事件监听器，实现了 OnEvent(event T) 方法的对象，或者是 func(event T) 函数
+*/
+func AddEventListener(listener interface{}) {
+	getApp().AddEventListener(listener)
+}
+
 /**
 运行程序
 */
@@ -79,3 +124,10 @@ func Run(environment env.Environment, options ...Option) (err error) {
 	}
 	return err
 }
+
+/**
+运行到结束，Runner 执行完成之后执行所有的销毁处理并返回退出码，一般用法：os.Exit(sparrow.RunOnce(env.New(), sparrow.WithRunner(...)))
+*/
+func RunOnce(environment env.Environment, options ...Option) (exitCode int) {
+	return getApp().RunOnce(environment, options...)
+}
@@ -1,10 +1,13 @@
 package sparrow
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"github.com/xkgo/sparrow/annotations"
 	"github.com/xkgo/sparrow/env"
 	"github.com/xkgo/sparrow/logger"
+	"github.com/xkgo/sparrow/util/ConvertUtils"
 	"github.com/xkgo/sparrow/util/GoUtils"
 	"github.com/xkgo/sparrow/util/ReflectUtils"
 	"os"
@@ -14,12 +17,26 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
 )
 
 const (
 	PropertyKeyApplicationName = "sparrow.application.name"
+	PropertyKeyShutdownTimeout = "sparrow.shutdown.timeout" // 优雅退出超时时间，超时后强制退出进程，如：30s
+	PropertyKeyStartupLog      = "sparrow.startup.log"      // 是否打印启动日志（Bean 初始化顺序以及 Init 耗时），默认 false
+	PropertyKeyStartupTimeout  = "sparrow.startup.timeout"  // 启动超时时间，作为 Init 方法 context.Context 参数的 deadline，如：60s
 )
 
+// 默认的优雅退出超时时间
+const DefaultShutdownTimeout = 30 * time.Second
+
+// 默认的启动超时时间
+const DefaultStartupTimeout = 60 * time.Second
+
+var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+
 type application interface {
 	/**
 	  注册Bean, 允许多个别名
@@ -27,7 +44,7 @@ type application interface {
 	  @param beanName bean的名称
 	  @param primary 是否是主bean
 	*/
-	RegisterBean(beanPtr interface{}, beanName string, primary bool)
+	RegisterBean(beanPtr interface{}, beanName string, primary bool, options ...BeanOption)
 
 	/**
 	  注册 PropertiesBean, 允许多个别名
@@ -36,7 +53,25 @@ type application interface {
 	  @param keyPrefix key前缀，会直接拼接，如果注意有必要的话需要加 .
 	  @param primary 是否是主bean
 	*/
-	RegisterPropertiesBean(beanPtr interface{}, beanName string, keyPrefix string, primary bool)
+	RegisterPropertiesBean(beanPtr interface{}, beanName string, keyPrefix string, primary bool, options ...BeanOption)
+
+	/**
+	  通过工厂方法注册Bean，工厂方法参数会按照类型从容器中解析注入
+	  @param fn 工厂方法，格式：func(deps...) *T 或者 func(deps...) (*T, error)
+	  @param beanName bean的名称，为空则使用 T 的类型名称
+	  @param primary 是否是主bean
+	*/
+	RegisterFactory(fn interface{}, beanName string, primary bool, options ...BeanOption)
+
+	/**
+	  注册自定义作用域，通过 WithScope(name) 使用
+	*/
+	RegisterScope(name string, scope Scope)
+
+	/**
+	  注册别名，别名可以指向 beanName 或者另一个别名
+	*/
+	RegisterAlias(alias, beanName string)
 
 	/**
 	  获取指定名称的Bean，不存在则返回 nil
@@ -54,16 +89,46 @@ type application interface {
 	GetBeanByType(beanTemplate interface{}) (beanPtr interface{}, err error)
 
 	/**
-	  获取所有的 BeanNames
+	  获取所有的 BeanNames，包括别名
 	*/
 	GetBeanNames() []string
 
+	/**
+	  计算依赖图，可以导出为 DOT 或者 JSON 格式
+	*/
+	DependencyGraph() *DependencyGraph
+
 	AppendBeforeInitHandler(handler Runner)
 
+	/**
+	  发布事件，实现了 OnEvent(event T) 方法的 Bean 以及 AddEventListener 添加的监听器会按照参数类型接收事件
+	*/
+	Publish(event interface{})
+
+	/**
+	  添加事件监听器，实现了 OnEvent(event T) 方法的对象，或者是 func(event T) 函数
+	*/
+	AddEventListener(listener interface{})
+
+	/**
+	  获取根 context，程序退出的时候会被取消
+	*/
+	Context() context.Context
+
+	/**
+	  触发优雅退出，效果等同于收到 SIGTERM 信号
+	*/
+	Shutdown()
+
 	/**
 	  运行程序
 	*/
 	Run(environment env.Environment, options ...Option) (err error)
+
+	/**
+	  运行到结束，Runner 执行完成后执行销毁处理并返回退出码
+	*/
+	RunOnce(environment env.Environment, options ...Option) (exitCode int)
 }
 
 type Application struct {
@@ -73,8 +138,29 @@ type Application struct {
 	destroyer          Runner                     // 程序结束，执行销毁
 	container          map[string]*BeanDefinition // 容器，key 为beanName
 	beforeInitHandlers []Runner                   // 初始化之前执行
+	initializedBeans   []*BeanDefinition          // 按照初始化完成的顺序记录的 Bean，程序退出时逆序执行销毁
+	contextRunner      ContextRunner              // 环境、ioc初始化完成后在 Goroutine 中执行，程序退出时 context 会被取消
+	ctx                context.Context            // 根 context，程序退出时取消
+	cancel             context.CancelFunc         // 取消根 context
+	quit               chan os.Signal             // 退出信号
+	exitAfterRunner    bool                       // Runner 执行完成之后直接退出，不再等待退出信号，适用于批处理、命令行程序
+	scopes             map[string]Scope           // 作用域，key 为作用域名称
+	conditionReport    []*ConditionEvaluation     // 条件注册报告
+	modules            []Module                   // 自动配置模块
+	postProcessors     []BeanPostProcessor        // Bean 后置处理器
+	eventListeners     []*eventListener           // 通过 AddEventListener 添加的事件监听器
+	parent             *Application               // 父容器，当前容器找不到的 Bean 会到父容器中查找
+	aliases            map[string]string          // 别名，key 为别名，value 为 beanName 或者另一个别名
+	lock               sync.RWMutex               // 容器锁，保护 Bean 的注册表以及初始化状态，执行工厂方法、Init、BeanPostProcessor 等用户代码时不持有
+	started            bool                       // 是否已经开始初始化，之后注册的 Bean 会立即完成注入
+	initWorkers        int                        // 并行初始化的 Goroutine 数量，0 表示串行初始化
+	creating           creatingBeans              // 正在创建的单例 Bean
+	startupCtx         context.Context            // 启动过程中传给 Init 方法的 context，带有启动超时时间
 }
 
+// 强制退出进程，测试时可以替换
+var osExit = os.Exit
+
 /**
 创建 Application
 */
@@ -82,82 +168,286 @@ func NewApplication() *Application {
 	return &Application{
 		container:          make(map[string]*BeanDefinition),
 		beforeInitHandlers: make([]Runner, 0),
+		scopes: map[string]Scope{
+			ScopePrototype: &prototypeScope{},
+		},
 	}
 }
 
-func (a *Application) doRegisterBean(beanPtr interface{}, beanName string, keyPrefix string, propertiesBean, changedListen, primary bool) {
+/**
+创建子容器，按名称、按类型查找 Bean（包括 @Inject 以及工厂方法参数）时当前容器找不到的话会到父容器中查找，
+Slice 注入以及 GetBeansOfType 只处理当前容器；运行时会合并父容器的 Environment（当前环境优先）。
+父容器需要先完成初始化，子容器的 Bean 销毁不会影响父容器
+*/
+func NewChildApplication(parent *Application) *Application {
+	a := NewApplication()
+	a.parent = parent
+	return a
+}
+
+/**
+获取父容器，没有的话返回 nil
+*/
+func (a *Application) Parent() *Application {
+	return a.parent
+}
+
+func (a *Application) doRegisterBean(beanPtr interface{}, beanName string, keyPrefix string, propertiesBean, changedListen, primary bool, options []BeanOption) {
+
+	a.registerBeanDefinition(newBeanDefinition(beanPtr, beanName, keyPrefix, propertiesBean, changedListen, primary), options)
+}
 
-	bd := newBeanDefinition(beanPtr, beanName, keyPrefix, propertiesBean, changedListen, primary)
+func (a *Application) registerBeanDefinition(bd *BeanDefinition, options []BeanOption) {
+	for _, option := range options {
+		option(bd)
+	}
+	if !a.addBeanDefinition(bd) {
+		return
+	}
+
+	// 程序已经开始初始化，直接完成注入以及初始化，失败的话撤销注册
+	if err := a.wireLateBean(bd); err != nil {
+		a.lock.Lock()
+		a.unregisterBeanDefinition(bd)
+		a.lock.Unlock()
+		err = errors.New("Bean(" + bd.Name + ")注册失败: " + err.Error())
+		logger.Error(err)
+		panic(err)
+	}
+}
+
+/**
+添加到注册表（包括别名），返回是否需要立即完成注入
+*/
+func (a *Application) addBeanDefinition(bd *BeanDefinition) (late bool) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if !bd.IsSingleton() {
+		if bd.IsPropertiesBean {
+			panic(errors.New("配置Bean(" + bd.Name + ")只能是单例"))
+		}
+		if !bd.Factory.IsValid() && bd.Type.Kind() != reflect.Ptr {
+			panic(errors.New("非单例Bean(" + bd.Name + ")必须是指针或者通过工厂方法注册"))
+		}
+	}
 
 	if bd, ok := a.container[bd.Name]; ok {
 		err := errors.New("BeanName(" + bd.Name + ")已经注册过了：" + bd.Type.String() + ", 请勿重复注册")
 		logger.Error(err)
 		panic(err)
 	}
+	if _, ok := a.aliases[bd.Name]; ok {
+		err := errors.New("BeanName(" + bd.Name + ")与已注册的别名冲突：" + strings.Join(a.aliasChain(bd.Name), " -> "))
+		logger.Error(err)
+		panic(err)
+	}
 	a.container[bd.Name] = bd
+	for _, alias := range bd.Aliases {
+		a.registerAlias(alias, bd.Name)
+	}
+	return a.started
 }
 
-func (a *Application) RegisterBean(beanPtr interface{}, beanName string, primary bool) {
-	a.doRegisterBean(beanPtr, beanName, "", false, false, primary)
+/**
+初始化完成之后注册的 Bean：先检查注册条件，不满足的话撤销注册，满足的话立即完成注入以及初始化（延迟初始化以及非单例 Bean 除外）
+*/
+func (a *Application) wireLateBean(bd *BeanDefinition) (err error) {
+	if len(bd.Conditions) > 0 {
+		evaluation := &ConditionEvaluation{BeanName: bd.Name, Matched: true}
+		a.matchConditions(evaluation, bd, false)
+		a.matchConditions(evaluation, bd, true)
+		a.lock.Lock()
+		a.conditionReport = append(a.conditionReport, evaluation)
+		if !evaluation.Matched {
+			a.unregisterBeanDefinition(bd)
+		}
+		a.lock.Unlock()
+		logger.Info("条件注册报告：\n" + evaluation.String())
+		if !evaluation.Matched {
+			return nil
+		}
+	}
+	if bd.Lazy || !bd.IsSingleton() {
+		return nil
+	}
+	return a.wireBean(bd, nil)
+}
+
+/**
+撤销注册，同时移除 Bean 注册时候设置的别名，调用方需要持有容器锁
+*/
+func (a *Application) unregisterBeanDefinition(bd *BeanDefinition) {
+	delete(a.container, bd.Name)
+	for _, alias := range bd.Aliases {
+		delete(a.aliases, alias)
+	}
+}
+
+func (a *Application) RegisterBean(beanPtr interface{}, beanName string, primary bool, options ...BeanOption) {
+	a.doRegisterBean(beanPtr, beanName, "", false, false, primary, options)
+}
+
+func (a *Application) RegisterPropertiesBean(beanPtr interface{}, beanName string, keyPrefix string, primary bool, options ...BeanOption) {
+	a.doRegisterBean(beanPtr, beanName, keyPrefix, true, false, primary, options)
 }
 
-func (a *Application) RegisterPropertiesBean(beanPtr interface{}, beanName string, keyPrefix string, primary bool) {
-	a.doRegisterBean(beanPtr, beanName, keyPrefix, true, false, primary)
+func (a *Application) RegisterPropertiesBeanListen(beanPtr interface{}, beanName string, keyPrefix string, changedListen, primary bool, options ...BeanOption) {
+	a.doRegisterBean(beanPtr, beanName, keyPrefix, true, changedListen, primary, options)
 }
 
-func (a *Application) RegisterPropertiesBeanListen(beanPtr interface{}, beanName string, keyPrefix string, changedListen, primary bool) {
-	a.doRegisterBean(beanPtr, beanName, keyPrefix, true, changedListen, primary)
+func (a *Application) RegisterFactory(fn interface{}, beanName string, primary bool, options ...BeanOption) {
+	a.registerBeanDefinition(newFactoryBeanDefinition(fn, beanName, primary), options)
+}
+
+func (a *Application) RegisterScope(name string, scope Scope) {
+	if name == ScopeSingleton || name == ScopePrototype {
+		panic(errors.New("内置作用域[" + name + "]不允许覆盖"))
+	}
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if a.scopes == nil {
+		a.scopes = make(map[string]Scope)
+	}
+	a.scopes[name] = scope
 }
 
 func (a *Application) GetBeanByName(beanName string) (beanPtr interface{}) {
-	if bd, ok := a.container[beanName]; ok {
-		return bd.Bean
+	if owner, bd := a.lookupBeanDefinition(beanName); bd != nil {
+		return owner.getBeanOrNil(bd)
 	}
 	return nil
 }
 
-func (a *Application) GetBeansOfType(beanType reflect.Type) (beansPtr map[string]interface{}) {
-	beansPtr = make(map[string]interface{})
-	if len(a.container) < 1 {
-		return
+/**
+按照名称（或者别名）查找 BeanDefinition，当前容器找不到的话逐级到父容器中查找，同时返回 BeanDefinition 所在的容器
+*/
+func (a *Application) lookupBeanDefinition(beanName string) (owner *Application, bd *BeanDefinition) {
+	for owner = a; owner != nil; owner = owner.parent {
+		owner.lock.RLock()
+		bd, ok := owner.container[owner.canonicalBeanName(beanName)]
+		owner.lock.RUnlock()
+		if ok {
+			return owner, bd
+		}
 	}
+	return nil, nil
+}
 
+/**
+按照类型（以及限定标签）查找 Primary BeanDefinition，当前容器中没有匹配的 Bean 才会到父容器中查找，同时返回 BeanDefinition 所在的容器
+*/
+func (a *Application) lookupPrimaryBeanDefinitionOfType(beanType reflect.Type, qualifier string) (owner *Application, bd *BeanDefinition, err error) {
+	bd, err = a.getPrimaryBeanDefinitionOfType(beanType, qualifier)
+	if err == nil {
+		return a, bd, nil
+	}
+	if a.parent != nil && len(a.getQualifiedBeanDefinitionsOfType(beanType, qualifier)) == 0 {
+		return a.parent.lookupPrimaryBeanDefinitionOfType(beanType, qualifier)
+	}
+	return nil, nil, err
+}
+
+/**
+获取 owner 容器中的 Bean 实例，父容器中的 Bean 不会依赖子容器，所以不需要传递依赖链
+*/
+func (a *Application) getOwnedBeanInstance(owner *Application, bd *BeanDefinition, dependencies []*dependencyStep) (bean interface{}, err error) {
+	if owner != a {
+		return owner.getBeanInstance(bd, nil)
+	}
+	return a.getBeanInstance(bd, dependencies)
+}
+
+/**
+获取 Bean 实例，单例直接返回当前的实例，其他作用域创建失败的话返回 nil
+*/
+func (a *Application) getBeanOrNil(bd *BeanDefinition) (beanPtr interface{}) {
+	a.lock.RLock()
+	if bd.IsSingleton() && (bd.Ready || !bd.Lazy) {
+		beanPtr = bd.Bean
+		a.lock.RUnlock()
+		return beanPtr
+	}
+	a.lock.RUnlock()
+	bean, err := a.getBeanInstance(bd, nil)
+	if err != nil {
+		logger.Error("获取Bean["+bd.Name+"]实例失败: ", err)
+		return nil
+	}
+	return bean
+}
+
+func (a *Application) GetBeansOfType(beanType reflect.Type) (beansPtr map[string]interface{}) {
+	beansPtr = make(map[string]interface{})
+	bds := make(map[string]*BeanDefinition)
+	a.lock.RLock()
 	for beanName, bd := range a.container {
 		if bd.Type == beanType {
-			beansPtr[beanName] = bd.Bean
+			bds[beanName] = bd
 		}
 	}
+	a.lock.RUnlock()
+
+	for beanName, bd := range bds {
+		beansPtr[beanName] = a.getBeanOrNil(bd)
+	}
 	return
 }
 
 func (a *Application) GetBeanByType(beanTemplate interface{}) (beanPtr interface{}, err error) {
+	beanType := resolveBeanType(beanTemplate)
+	owner, bd, err := a.lookupPrimaryBeanDefinitionOfType(beanType, "")
+	if err != nil {
+		return nil, err
+	}
+	instance, err := owner.lookupBeanInstance(bd)
+	if err != nil {
+		return nil, err
+	}
+	bean, err := ReflectUtils.ConvertTo(instance, beanType)
+	if err == nil {
+		return bean.Interface(), nil
+	}
+	return nil, replacedBeanError(bd, instance, beanType, err)
+}
+
+/**
+对外获取 Bean 实例：容器还没有开始初始化的话，非延迟加载的单例直接返回注册的对象（初始化的时候再统一注入），否则按需创建
+*/
+func (a *Application) lookupBeanInstance(bd *BeanDefinition) (bean interface{}, err error) {
+	a.lock.RLock()
+	if !a.started && bd.IsSingleton() && !bd.Lazy {
+		bean = bd.Bean
+		a.lock.RUnlock()
+		return bean, nil
+	}
+	a.lock.RUnlock()
+	return a.getBeanInstance(bd, nil)
+}
+
+/**
+根据 bean 模板计算 bean 类型，模板可以是 reflect.Type、reflect.Value 或者对象（指针），非指针类型统一转成指针类型
+*/
+func resolveBeanType(beanTemplate interface{}) reflect.Type {
 	beanType, ok := beanTemplate.(reflect.Type)
 	if !ok {
-		beanType = reflect.TypeOf(beanTemplate)
-	} else {
 		if val, ok := beanTemplate.(reflect.Value); ok {
 			beanType = val.Type()
+		} else {
+			beanType = reflect.TypeOf(beanTemplate)
 		}
 	}
 	if beanType.Kind() != reflect.Ptr {
 		beanType = reflect.PtrTo(beanType)
 	}
-	bd, err := a.getPrimaryBeanDefinitionOfType(beanType)
-	if err != nil {
-		return nil, err
-	}
-	bean, err := ReflectUtils.ConvertTo(bd.Bean, beanType)
-	if err == nil {
-		return bean.Interface(), nil
-	}
-	return nil, err
+	return beanType
 }
 
 /**
 获取 primary bean definition
 */
-func (a *Application) getPrimaryBeanDefinitionOfType(beanType reflect.Type) (bd *BeanDefinition, err error) {
-	bds := a.getBeanDefinitionsOfType(beanType)
+func (a *Application) getPrimaryBeanDefinitionOfType(beanType reflect.Type, qualifier string) (bd *BeanDefinition, err error) {
+	bds := a.getQualifiedBeanDefinitionsOfType(beanType, qualifier)
 	if len(bds) == 1 {
 		for _, item := range bds {
 			return item, nil
@@ -170,10 +460,28 @@ func (a *Application) getPrimaryBeanDefinitionOfType(beanType reflect.Type) (bd
 			}
 		}
 	}
+	if len(qualifier) > 0 {
+		return nil, errors.New("无法找到类型（" + beanType.String() + "）限定标签（" + qualifier + "）的 Primary BeanDefinition，期望 1, 实际:" + strconv.FormatInt(int64(len(bds)), 10))
+	}
 	return nil, errors.New("无法找到类型（" + beanType.String() + "）的 Primary BeanDefinition，期望 1, 实际:" + strconv.FormatInt(int64(len(bds)), 10))
 }
 
+/**
+获取指定类型并且具有限定标签的 BeanDefinition，qualifier 为空的话不过滤
+*/
+func (a *Application) getQualifiedBeanDefinitionsOfType(beanType reflect.Type, qualifier string) (bds map[string]*BeanDefinition) {
+	bds = a.getBeanDefinitionsOfType(beanType)
+	for beanName, bd := range bds {
+		if !bd.HasQualifier(qualifier) {
+			delete(bds, beanName)
+		}
+	}
+	return
+}
+
 func (a *Application) getBeanDefinitionsOfType(beanType reflect.Type) (bds map[string]*BeanDefinition) {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
 	bds = make(map[string]*BeanDefinition)
 	if len(a.container) < 1 {
 		return
@@ -192,11 +500,18 @@ func (a *Application) getBeanDefinitionsOfType(beanType reflect.Type) (bds map[s
 }
 
 func (a *Application) GetBeanNames() []string {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
 	beanNames := make([]string, 0)
 
 	for beanName, _ := range a.container {
 		beanNames = append(beanNames, beanName)
 	}
+	for alias := range a.aliases {
+		if _, ok := a.container[a.canonicalBeanName(alias)]; ok {
+			beanNames = append(beanNames, alias)
+		}
+	}
 
 	return beanNames
 }
@@ -214,23 +529,50 @@ func (a *Application) Run(environment env.Environment, options ...Option) (err e
 		logger.Flush()
 	}()
 
+	if a.parent != nil && a.parent.Environment != nil {
+		// 合并父容器的配置，当前环境的配置优先
+		environment.Merge(a.parent.Environment)
+	}
 	a.Environment = environment
+	a.ctx, a.cancel = context.WithCancel(context.Background())
+	defer a.cancel()
+
+	// 尽早监听退出信号，初始化过程中收到的信号会在初始化完成之后处理
+	a.quit = make(chan os.Signal, 2)
+	signal.Notify(a.quit, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
+	defer signal.Stop(a.quit)
+
 	if a.container == nil {
 		a.container = make(map[string]*BeanDefinition)
 	}
 	if name, ok := environment.GetProperty(PropertyKeyApplicationName); ok && len(name) > 0 {
-		app.Name = name
+		a.Name = name
 	} else {
 		// 直接通过命令行参数计算
-		app.Name = filepath.Base(os.Args[0])
+		a.Name = filepath.Base(os.Args[0])
 	}
 
 	if len(options) > 0 {
 		for _, option := range options {
-			option(app)
+			option(a)
 		}
 	}
 
+	// 注册自动配置模块
+	err = a.registerModules()
+	if err != nil {
+		return
+	}
+
+	// 配置变更事件转发到事件总线，单个 Key 的变更为 *env.KeyChangeEvent，一次刷新的所有变更为 *env.ChangeSet
+	environment.Subscribe("*", func(event *env.KeyChangeEvent) {
+		a.Publish(event)
+	})
+	environment.SubscribeChangeSet(func(changes *env.ChangeSet) {
+		a.Publish(changes)
+	})
+	a.Publish(&EnvironmentPreparedEvent{App: a})
+
 	if len(a.beforeInitHandlers) > 0 {
 		for _, handler := range a.beforeInitHandlers {
 			err := handler(a)
@@ -244,30 +586,273 @@ func (a *Application) Run(environment env.Environment, options ...Option) (err e
 	err = a.init()
 
 	if err != nil {
+		// 已经初始化成功的 Bean 需要销毁，避免资源泄露
+		_ = a.destroyBeans()
 		return
 	}
+	a.logStartupReport()
+	a.Publish(&BeansWiredEvent{App: a})
+	a.Publish(&ApplicationStartedEvent{App: a})
 
 	if nil != a.runner {
-		err = a.runner(a)
+		var sig os.Signal
+		sig, err = a.runRunner()
 		if err != nil {
+			_ = a.shutdown(nil)
+			return
+		}
+		if sig != nil {
+			// Runner 执行过程中已经收到退出信号，不再启动 ContextRunner，直接执行优雅退出
+			err = a.shutdown(nil)
 			return
 		}
 	}
 
-	// 等待退出
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, os.Interrupt)
-	<-quit
+	// 支持 context 的 runner 放到 Goroutine 中执行，程序退出时通过 context 通知其结束
+	var runnerDone chan error
+	if nil != a.contextRunner {
+		runnerDone = make(chan error, 1)
+		runGoroutine(func() {
+			runnerDone <- a.contextRunner(a.ctx, a)
+		}, func(r interface{}) {
+			runnerDone <- fmt.Errorf("ContextRunner 执行发生panic: %v", r)
+		})
+	}
+	a.Publish(&ApplicationReadyEvent{App: a})
+
+	// 等待退出信号，或者 ContextRunner 异常结束
+	runnerDone, err = a.awaitShutdown(runnerDone)
+
+	// 执行优雅退出
+	if serr := a.shutdown(runnerDone); serr != nil && err == nil {
+		err = serr
+	}
+	return
+}
+
+/**
+运行到结束：Runner 执行完成后执行所有的销毁处理然后返回，不等待退出信号，适用于定时任务、数据迁移、命令行工具等
+@return exitCode 根据 Runner 的返回值计算的退出码，参考 ExitCode
+*/
+func (a *Application) RunOnce(environment env.Environment, options ...Option) (exitCode int) {
+	options = append(options, WithExitAfterRunner())
+	err := a.Run(environment, options...)
+	if nil != err {
+		logger.Error("程序运行异常退出: ", err)
+	}
+	return ExitCode(err)
+}
+
+/**
+执行 Runner，执行期间收到的退出信号会取消 a.Context()，阻塞的 Runner（例如 HTTP 服务）可以据此结束
+@return sig Runner 执行期间收到的退出信号，没有收到的话返回 nil
+*/
+func (a *Application) runRunner() (sig os.Signal, err error) {
+	runnerFinished := make(chan struct{})
+	watcherFinished := make(chan struct{})
+	runGoroutine(func() {
+		defer close(watcherFinished)
+		select {
+		case sig = <-a.quit:
+			logger.Info("收到退出信号: ", sig, ", 通知 Runner 结束")
+			a.cancel()
+		case <-runnerFinished:
+		}
+	}, nil)
+
+	err = a.runner(a)
+	close(runnerFinished)
+	// 等待信号监听结束之后再读取 sig，避免和监听 Goroutine 并发访问
+	<-watcherFinished
+	return
+}
+
+/**
+等待退出信号，如果 ContextRunner 返回了 error，那么直接返回该 error 并开始退出，正常结束的话继续等待退出信号
+@return pending ContextRunner 仍未结束的话返回其结果通道，否则返回 nil
+*/
+func (a *Application) awaitShutdown(runnerDone chan error) (pending chan error, err error) {
+	pending = runnerDone
+	if a.exitAfterRunner && pending == nil {
+		return nil, nil
+	}
+	for {
+		select {
+		case sig := <-a.quit:
+			logger.Info("收到退出信号: ", sig, ", 开始执行优雅退出")
+			return pending, nil
+		case err = <-pending:
+			// 已经结束，不再等待
+			pending = nil
+			if err != nil {
+				logger.Error("ContextRunner 异常结束, 开始执行优雅退出: ", err)
+				return pending, err
+			}
+			if a.exitAfterRunner {
+				logger.Info("ContextRunner 执行完成, 开始退出")
+				return pending, nil
+			}
+		}
+	}
+}
+
+/**
+优雅退出：取消根 context，等待 ContextRunner 结束，然后执行 destroyer 和 Bean 的销毁方法；
+超过 sparrow.shutdown.timeout 或者再次收到退出信号的话直接强制退出进程
+*/
+func (a *Application) shutdown(runnerDone chan error) (err error) {
+	a.Publish(&ShutdownStartedEvent{App: a})
+	a.cancel()
+
+	timeout := a.getShutdownTimeout()
+	finished := make(chan struct{})
+	defer close(finished)
+
+	runGoroutine(func() {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		select {
+		case <-finished:
+			return
+		case <-timer.C:
+			logger.Error("优雅退出超时(", timeout, ")，强制退出")
+		case sig := <-a.quit:
+			logger.Warn("再次收到退出信号: ", sig, ", 立即退出")
+		}
+		logger.Flush()
+		osExit(1)
+	}, nil)
+
+	if nil != runnerDone {
+		// 等待 ContextRunner 结束
+		select {
+		case rerr := <-runnerDone:
+			if rerr != nil {
+				err = rerr
+			}
+		case <-time.After(timeout):
+		}
+	}
 
-	// 自动执行注册过来的Bean的销毁方法
 	// 程序结束
 	if nil != a.destroyer {
-		err = a.destroyer(a)
-		if err != nil {
-			return
+		if derr := a.destroyer(a); derr != nil {
+			logger.Error("执行 Destroyer 异常: ", derr)
+			if err == nil {
+				err = derr
+			}
 		}
 	}
 
+	// 自动执行注册过来的Bean的销毁方法
+	if derr := a.destroyBeans(); derr != nil && err == nil {
+		err = derr
+	}
+	return
+}
+
+/**
+获取优雅退出的超时时间，支持 time.Duration 格式（如 30s），纯数字表示秒
+*/
+func (a *Application) getShutdownTimeout() time.Duration {
+	return a.getTimeoutProperty(PropertyKeyShutdownTimeout, DefaultShutdownTimeout, "优雅退出超时时间")
+}
+
+/**
+获取启动超时时间，Init 方法的 context.Context 参数在超时后会被取消
+*/
+func (a *Application) getStartupTimeout() time.Duration {
+	return a.getTimeoutProperty(PropertyKeyStartupTimeout, DefaultStartupTimeout, "启动超时时间")
+}
+
+/**
+读取超时时间配置，支持秒数（如：30）或者 time.Duration 格式（如：30s、1m），非法配置以及小于等于 0 的配置使用默认值
+*/
+func (a *Application) getTimeoutProperty(key string, def time.Duration, desc string) time.Duration {
+	if a.Environment == nil {
+		return def
+	}
+	value := a.Environment.GetPropertyWithDef(key, def.String())
+	timeout, err := time.ParseDuration(value)
+	if seconds, serr := ConvertUtils.ToInt64(value); serr == nil {
+		timeout, err = time.Duration(seconds)*time.Second, nil
+	}
+	if err != nil || timeout <= 0 {
+		logger.Warn("非法的"+desc+"配置["+key+"="+value+"], 使用默认值: ", def)
+		return def
+	}
+	return timeout
+}
+
+/**
+获取根 context，程序退出的时候会被取消
+*/
+func (a *Application) Context() context.Context {
+	if a.ctx == nil {
+		return context.Background()
+	}
+	return a.ctx
+}
+
+/**
+触发优雅退出，效果等同于收到 SIGTERM 信号
+*/
+func (a *Application) Shutdown() {
+	if a.quit == nil {
+		return
+	}
+	select {
+	case a.quit <- syscall.SIGTERM:
+	default:
+	}
+}
+
+/**
+使用 Goroutine 执行，并且自动进行panic异常cache，和 GoUtils.RunGoroutine 不同的是新的 Goroutine 会绑定自己的 context（沿用当前的 traceId），
+不会和当前 Goroutine 共享同一个调用上下文
+*/
+func runGoroutine(handler func(), panicHandler func(r interface{}), afterHandlers ...func()) {
+	traceId := GoUtils.GetTraceId()
+	go func() {
+		GoUtils.BindContextWithTraceId(nil, traceId)
+		defer GoUtils.UnbindContext()
+		GoUtils.Run(handler, panicHandler, afterHandlers...)
+	}()
+}
+
+/**
+按照初始化顺序的逆序执行 Bean 的 Destroy 方法，单个 Bean 销毁失败（error 或者 panic）不影响其他 Bean 的销毁，
+所有的失败会合并成一个 error 返回
+*/
+func (a *Application) destroyBeans() (err error) {
+	a.lock.Lock()
+	beans := a.initializedBeans
+	a.initializedBeans = nil
+	a.started = false
+	a.lock.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), a.getShutdownTimeout())
+	defer cancel()
+
+	errMsgs := make([]string, 0)
+	for i := len(beans) - 1; i >= 0; i-- {
+		bd := beans[i]
+		if !bd.DestroyFn.IsValid() {
+			continue
+		}
+		GoUtils.Run(func() {
+			if derr := a.doInitOrDestroy(ctx, bd, false); derr != nil {
+				errMsgs = append(errMsgs, "Bean["+bd.Name+"]销毁失败: "+derr.Error())
+			}
+		}, func(r interface{}) {
+			errMsgs = append(errMsgs, fmt.Sprint("Bean[", bd.Name, "]销毁发生panic: ", r))
+		})
+	}
+
+	if len(errMsgs) > 0 {
+		err = errors.New("执行Bean销毁异常：\n" + strings.Join(errMsgs, "\n"))
+		logger.Error(err)
+	}
 	return
 }
 
@@ -277,20 +862,53 @@ func (a *Application) Run(environment env.Environment, options ...Option) (err e
 2. 执行函数初始化方法
 */
 func (a *Application) init() (err error) {
-	// 自动注入处理
-	err = a.autoInjectProcess()
+	ctx, cancel := context.WithTimeout(a.Context(), a.getStartupTimeout())
+	a.lock.Lock()
+	a.started = true
+	a.startupCtx = ctx
+	a.lock.Unlock()
+	defer func() {
+		cancel()
+		a.lock.Lock()
+		a.startupCtx = nil
+		a.lock.Unlock()
+	}()
+
+	// 条件注册处理，不满足条件的 Bean 从容器中移除
+	a.evaluateConditions()
+
+	// 先初始化 BeanPostProcessor
+	err = a.initBeanPostProcessors()
 	if nil != err {
 		return
 	}
 
-	return
+	// 自动注入处理
+	return a.autoInjectProcess()
+}
+
+/**
+当前注册的所有 BeanDefinition，按照名称排序
+*/
+func (a *Application) beanDefinitions() []*BeanDefinition {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	bds := make([]*BeanDefinition, 0, len(a.container))
+	for _, name := range a.sortedBeanNames() {
+		bds = append(bds, a.container[name])
+	}
+	return bds
 }
 
 func (a *Application) autoInjectProcess() (err error) {
-	if len(a.container) < 1 {
-		return
+	if a.initWorkers > 0 {
+		return a.parallelInjectProcess()
 	}
-	for _, bd := range a.container {
+	for _, bd := range a.beanDefinitions() {
+		if bd.Lazy {
+			// 延迟初始化，被其他 Bean 依赖的话会在注入的时候初始化
+			continue
+		}
 		err = a.wireBean(bd, nil)
 		if nil != err {
 			return
@@ -300,196 +918,488 @@ func (a *Application) autoInjectProcess() (err error) {
 }
 
 /**
-执行注入
+执行注入，只处理单例 Bean，其他作用域的 Bean 在每次获取的时候创建
 */
-func (a *Application) wireBean(bd *BeanDefinition, dependencies []*BeanDefinition) (err error) {
-	if bd.Ready {
-		// 已经初始化过了
+func (a *Application) wireBean(bd *BeanDefinition, dependencies []*dependencyStep) (err error) {
+	if !bd.IsSingleton() {
 		return
 	}
+	_, err = a.getSingleton(bd, dependencies)
+	return
+}
+
+// 正在创建的单例 Bean，创建完成（成功或者失败）之后关闭对应的 chan
+type creatingBeans map[*BeanDefinition]chan struct{}
+
+/**
+获取单例 Bean，未初始化的话在当前 Goroutine 中完成注入以及初始化，其他 Goroutine 正在初始化的话等待其完成，
+创建过程中不持有容器锁，工厂方法、Init 方法中可以（在任意 Goroutine 中）获取其他 Bean
+*/
+func (a *Application) getSingleton(bd *BeanDefinition, dependencies []*dependencyStep) (bean interface{}, err error) {
+	a.lock.Lock()
+	if bd.Ready {
+		bean = bd.Bean
+		a.lock.Unlock()
+		return bean, nil
+	}
+	if err = dependencyCycleError(bd, dependencies); err != nil {
+		a.lock.Unlock()
+		return nil, err
+	}
+	if done, ok := a.creating[bd]; ok {
+		// 其他调用链正在创建（当前调用链上的在上面已经作为循环依赖返回）
+		a.lock.Unlock()
+		<-done
+		a.lock.RLock()
+		defer a.lock.RUnlock()
+		if !bd.Ready {
+			return nil, errors.New("Bean[" + bd.Name + "]初始化失败")
+		}
+		return bd.Bean, nil
+	}
+	done := a.markCreating(bd)
+	a.lock.Unlock()
+
+	defer a.finishCreating(bd, done)
+	if err = a.createSingleton(bd, dependencies); err != nil {
+		return nil, err
+	}
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	return bd.Bean, nil
+}
+
+/**
+标记单例 Bean 正在创建，调用方需要持有容器锁
+*/
+func (a *Application) markCreating(bd *BeanDefinition) (done chan struct{}) {
+	if a.creating == nil {
+		a.creating = make(creatingBeans)
+	}
+	done = make(chan struct{})
+	a.creating[bd] = done
+	return done
+}
+
+/**
+单例 Bean 创建结束（成功或者失败），通知等待的 Goroutine
+*/
+func (a *Application) finishCreating(bd *BeanDefinition, done chan struct{}) {
+	a.lock.Lock()
+	delete(a.creating, bd)
+	a.lock.Unlock()
+	close(done)
+}
 
+/**
+创建单例 Bean：配置Bean 直接绑定配置，其他的执行注入以及初始化，成功之后标记为 Ready
+*/
+func (a *Application) createSingleton(bd *BeanDefinition, dependencies []*dependencyStep) (err error) {
 	// 属性绑定
 	if bd.IsPropertiesBean {
-		bd.Bean, err = a.Environment.BindPropertiesListen(bd.KeyPrefix, bd.Bean, bd.ChangedListen)
+		bean, err := a.Environment.BindPropertiesListen(bd.KeyPrefix, bd.Bean, bd.ChangedListen)
 		if err != nil {
-			return err
+			return fmt.Errorf("配置Bean[%s]绑定失败: %w", bd.Name, err)
 		}
-		bd.Ready = true
 		// 计算排序
-		bd.Order, _ = ReflectUtils.GetRetInt64(bd.Bean, "GetOrder")
+		order, _ := ReflectUtils.GetRetInt64(bean, "GetOrder")
+		a.lock.Lock()
+		bd.Bean = bean
+		bd.Order = order
+		a.markReady(bd)
+		a.lock.Unlock()
 		return nil
 	}
 
-	if nil == dependencies {
-		dependencies = make([]*BeanDefinition, 0)
-	} else {
-		// 检查是否有循环注入问题（检查当前元素在依赖链中是否存在，存在的话说明具有循环依赖）
-		depChain := make([]string, 0)
-		cycleDep := false
-		for _, dbd := range dependencies {
-			if dbd.Type == bd.Type {
-				cycleDep = true
-			}
-			depChain = append(depChain, "|--("+dbd.Type.String()+")["+dbd.Name+"]\n|")
-		}
+	_, err = a.createBean(bd, dependencies)
+	if nil != err {
+		return
+	}
 
-		if cycleDep {
-			depChain = append(depChain, "|--("+bd.Type.String()+")["+bd.Name+"]")
-			return errors.New("存在循环依赖问题：\n\n" + strings.Join(depChain, "\n") + "\n")
+	// 标记状态
+	a.lock.Lock()
+	a.markReady(bd)
+	a.lock.Unlock()
+	return
+}
+
+func (a *Application) isReady(bd *BeanDefinition) bool {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	return bd.Ready
+}
+
+/**
+标记单例 Bean 初始化完成，调用方需要持有容器锁
+*/
+func (a *Application) markReady(bd *BeanDefinition) {
+	bd.Ready = true
+	a.initializedBeans = append(a.initializedBeans, bd)
+}
+
+/**
+获取 BeanDefinition 对应的 Bean 实例，单例直接返回（未初始化的话先初始化），其他作用域交给对应的 Scope 处理
+*/
+func (a *Application) getBeanInstance(bd *BeanDefinition, dependencies []*dependencyStep) (bean interface{}, err error) {
+	if bd.IsSingleton() {
+		return a.getSingleton(bd, dependencies)
+	}
+
+	a.lock.RLock()
+	scope, ok := a.scopes[bd.Scope]
+	a.lock.RUnlock()
+	if !ok && bd.Scope == ScopePrototype {
+		scope, ok = &prototypeScope{}, true
+	}
+	if !ok {
+		return nil, errors.New("Bean[" + bd.Name + "]的作用域[" + bd.Scope + "]未注册")
+	}
+	return scope.Get(bd, func() (interface{}, error) {
+		bean, err := a.createBean(bd, dependencies)
+		if err != nil {
+			return nil, err
 		}
+		return bean.Interface(), nil
+	})
+}
+
+/**
+创建 Bean 实例：工厂方法创建、单例直接使用注册的对象、其他作用域复制注册的模板对象，然后执行属性注入以及初始化方法，
+初始化方法前后会执行 BeanPostProcessor，单例 Bean 会直接设置到 bd 上
+*/
+func (a *Application) createBean(bd *BeanDefinition, dependencies []*dependencyStep) (bean reflect.Value, err error) {
+	target, bean, err := a.prepareBean(bd, dependencies)
+	if err != nil {
+		return bean, err
+	}
+	err = a.initBean(bd, target, bean, dependencies)
+	if err != nil {
+		return bean, err
 	}
+	return a.finishBean(bd, target)
+}
 
-	dependencies = append(dependencies, bd)
+/**
+创建 Bean 实例并完成注入，然后执行 BeanPostProcessor.BeforeInit，返回的 target 为单例的 bd 或者其他作用域的副本
+*/
+func (a *Application) prepareBean(bd *BeanDefinition, dependencies []*dependencyStep) (target *BeanDefinition, bean reflect.Value, err error) {
+	// 检查是否有循环注入问题
+	if err = dependencyCycleError(bd, dependencies); err != nil {
+		return nil, bean, err
+	}
 
-	// 执行自动注入
-	bt := reflect.TypeOf(bd.Bean)
-	if bt.Kind() == reflect.Ptr {
-		bt = bt.Elem()
+	current := &dependencyStep{bd: bd}
+	dependencies = append(dependencies, current)
+
+	if bd.Factory.IsValid() {
+		// 工厂方法创建
+		bean, err = a.invokeFactory(bd, dependencies)
+		if err != nil {
+			return nil, bean, err
+		}
+	} else if bd.IsSingleton() || bd.Type.Kind() != reflect.Ptr {
+		bean = bd.Value
+	} else {
+		// 复制模板对象
+		bean = reflect.New(bd.Type.Elem())
+		bean.Elem().Set(bd.Value.Elem())
 	}
 
-	bv := reflect.ValueOf(bd.Bean)
+	// 执行自动注入
+	bv := bean
 	if bv.Kind() == reflect.Ptr {
 		bv = bv.Elem()
 	}
+	bt := bv.Type()
 
 	// 遍历属性
-	for i := 0; i < bt.NumField(); i++ {
+	for i := 0; bt.Kind() == reflect.Struct && i < bt.NumField(); i++ {
 		tf := bt.Field(i) // 属性类型
 
 		inject, err := annotations.FindInject(tf.Tag)
 		if err != nil {
 			logger.Fatal("非法的@Inject 注解: ", err)
-			return err
+			return nil, bean, err
 		}
 		valueAnn, err := annotations.FindValue(tf.Tag)
 		if err != nil {
 			logger.Fatal("非法的@Value 注解: ", err)
-			return err
+			return nil, bean, err
 		}
 
 		if inject != nil && valueAnn != nil {
 			logger.Fatal("不允许同时设置 @Inject 和 @Value 注解")
-			return err
+			return nil, bean, err
 		}
 
 		if inject != nil {
+			current.field = tf.Name
 			err = a.wireBeanFieldByInjectAnnotation(bt.Field(i), bv.Field(i), inject, dependencies)
 			if nil != err {
-				return err
+				return nil, bean, err
 			}
 		}
 		if valueAnn != nil {
 			err = a.wireBeanFieldByValueAnnotation(valueAnn, bt.Field(i), bv.Field(i))
 			if nil != err {
-				return err
+				return nil, bean, err
 			}
 		}
 	}
 
-	err = a.doInitOrDestroy(bd, true)
-	if nil != err {
-		return
+	// 单例直接使用 bd，其他作用域使用副本，避免修改 bd
+	target = bd
+	if bd.IsSingleton() {
+		a.lock.Lock()
+		bd.setBean(bean)
+		a.lock.Unlock()
+	} else {
+		a.lock.RLock()
+		instanceBd := *bd
+		a.lock.RUnlock()
+		instanceBd.setBean(bean)
+		target = &instanceBd
 	}
 
-	// 标记状态
-	bd.Ready = true
-	// 计算排序
-	bd.Order, _ = ReflectUtils.GetRetInt64(bd.Bean, "GetOrder")
+	for _, processor := range a.getPostProcessors() {
+		if err = processor.BeforeInit(target); err != nil {
+			return nil, bean, errors.New("BeanPostProcessor 初始化前处理Bean[" + bd.Name + "]失败: " + err.Error())
+		}
+	}
+	return target, bean, nil
+}
+
+/**
+执行 Bean 的 Init 方法，并记录耗时，启动过程中 context.Context 参数带有启动超时时间，启动完成之后（延迟初始化、多例）使用根 context
+*/
+func (a *Application) initBean(bd *BeanDefinition, target *BeanDefinition, bean reflect.Value, dependencies []*dependencyStep) (err error) {
+	a.lock.RLock()
+	ctx := a.startupCtx
+	a.lock.RUnlock()
+	if ctx == nil {
+		ctx = a.Context()
+	}
+	initStart := time.Now()
+	err = a.invokeLifecycleMethod(ctx, bean.MethodByName("Init"), "Init", append(dependencies, &dependencyStep{bd: bd}))
+	if nil != err {
+		return fmt.Errorf("Bean[%s]执行Init方法失败: %w", bd.Name, err)
+	}
+	a.lock.Lock()
+	target.InitDuration = time.Since(initStart)
+	a.lock.Unlock()
 	return
 }
 
-func (a *Application) doInitOrDestroy(bd *BeanDefinition, init bool) (err error) {
-	fn := bd.InitFn
-	if !init {
-		fn = bd.DestroyFn
-	}
-	// 执行初始化 或者 destroy 方法
-	if fn.IsValid() {
-		args := make([]reflect.Value, 0)
-		for i := 0; i < fn.Type().NumIn(); i++ {
-			arg := fn.Type().In(i)
-			if arg.Kind() == reflect.Interface {
-				if reflect.TypeOf(a.Environment).Implements(arg) {
-					args = append(args, reflect.ValueOf(a.Environment))
-				}
-				if reflect.TypeOf(a).Implements(arg) {
-					args = append(args, reflect.ValueOf(a))
-				}
-			} else {
-				if arg == reflect.TypeOf(a) {
-					args = append(args, reflect.ValueOf(a))
-				} else {
-					err = errors.New("初始化参数必须是 en.Environment 或者 *Application")
-					return
-				}
-			}
+/**
+计算排序，然后执行 BeanPostProcessor.AfterInit，返回最终的 Bean（可能被替换成包装对象）
+*/
+func (a *Application) finishBean(bd *BeanDefinition, target *BeanDefinition) (bean reflect.Value, err error) {
+	// 计算排序
+	order, _ := ReflectUtils.GetRetInt64(target.Bean, "GetOrder")
+	a.lock.Lock()
+	bd.Order = order
+	a.lock.Unlock()
+
+	for _, processor := range a.getPostProcessors() {
+		replaced, err := processor.AfterInit(target)
+		if err != nil {
+			return bean, errors.New("BeanPostProcessor 初始化后处理Bean[" + bd.Name + "]失败: " + err.Error())
+		}
+		if replaced != nil {
+			// 替换成包装对象，销毁方法仍然使用原始对象的
+			a.lock.Lock()
+			target.Bean = replaced
+			target.Value = reflect.ValueOf(replaced)
+			a.lock.Unlock()
 		}
-		fn.Call(args)
 	}
-	return
+	return target.Value, nil
 }
 
 /**
-按照 @Inject 注解进行注入
+依赖链中的一个节点，field 为当前 Bean 正在注入的字段（工厂方法参数为 arg0、arg1...）
 */
-func (a *Application) wireBeanFieldByInjectAnnotation(tf reflect.StructField, vf reflect.Value, inject *annotations.InjectAnn, dependencies []*BeanDefinition) (err error) {
+type dependencyStep struct {
+	bd    *BeanDefinition
+	field string
+}
 
-	if tf.Type.Kind() == reflect.Slice {
-		et := tf.Type.Elem() // 元素类型
-		bds := a.getBeanDefinitionsOfType(et)
-		if len(bds) < 1 {
-			if inject.Required {
-				err = errors.New("Ref Beans(ByBeanType) not found: " + inject.Name)
-				logger.Fatal(err)
-				return err
-			}
-			return // 没有强制要求
+/**
+当前 Bean 已经在依赖链中（按照 BeanDefinition 判断）的话返回循环依赖错误
+*/
+func dependencyCycleError(bd *BeanDefinition, dependencies []*dependencyStep) error {
+	for i, step := range dependencies {
+		if step.bd == bd {
+			return errors.New("存在循环依赖问题：" + formatDependencyCycle(dependencies[i:], bd) +
+				"，可以通过 @Inject:\"lazy=true\" 注入 func() T 打破循环")
 		}
+	}
+	return nil
+}
 
-		beanList := reflect.New(tf.Type).Elem()
-		bdList := make([]*BeanDefinition, 0)
-		for _, bd := range bds {
-			if !bd.Ready { // 先处理以来问题
-				err = a.wireBean(bd, dependencies)
-				if err != nil {
-					return err
-				}
-			}
-			bdList = append(bdList, bd)
+/**
+复制依赖链，保留每个节点当前正在注入的字段
+*/
+func snapshotDependencies(dependencies []*dependencyStep) []*dependencyStep {
+	steps := make([]*dependencyStep, 0, len(dependencies))
+	for _, step := range dependencies {
+		steps = append(steps, &dependencyStep{bd: step.bd, field: step.field})
+	}
+	return steps
+}
+
+/**
+依赖链中仍在创建的单例 Bean，已经创建结束的节点不会再形成循环
+*/
+func (a *Application) creatingDependencies(chain []*dependencyStep) (dependencies []*dependencyStep) {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	for _, step := range chain {
+		if _, ok := a.creating[step.bd]; ok {
+			dependencies = append(dependencies, step)
 		}
+	}
+	return
+}
 
-		// 排序
-		sort.Slice(bdList, func(i, j int) bool {
-			o1 := bdList[i].Order
-			o2 := bdList[j].Order
-			return o2 < o1
-		})
+/**
+格式化循环依赖链，如：A.repo -> B.cache -> A
+*/
+func formatDependencyCycle(cycle []*dependencyStep, bd *BeanDefinition) string {
+	items := make([]string, 0, len(cycle)+1)
+	for _, step := range cycle {
+		items = append(items, step.bd.Name+"."+step.field)
+	}
+	return strings.Join(append(items, bd.Name), " -> ")
+}
 
-		for _, bd := range bdList {
-			element, err := ReflectUtils.ConvertTo(bd.Bean, et)
-			if err != nil || !element.IsValid() {
-				return err
-			}
-			beanList = reflect.Append(beanList, element)
+/**
+执行工厂方法创建 Bean，工厂方法的参数按照类型从容器中获取 Primary Bean，并且先完成参数 Bean 的注入和初始化
+*/
+func (a *Application) invokeFactory(bd *BeanDefinition, dependencies []*dependencyStep) (bean reflect.Value, err error) {
+	ft := bd.Factory.Type()
+	args := make([]reflect.Value, 0, ft.NumIn())
+	current := dependencies[len(dependencies)-1]
+	for i := 0; i < ft.NumIn(); i++ {
+		argType := ft.In(i)
+		current.field = "arg" + strconv.Itoa(i)
+		owner, argBd, err := a.lookupPrimaryBeanDefinitionOfType(argType, "")
+		if err != nil {
+			return bean, errors.New("Bean[" + bd.Name + "]工厂方法参数[" + strconv.Itoa(i) + "](" + argType.String() + ")无法解析: " + err.Error())
 		}
-		err = ReflectUtils.SetFieldValueByField(tf, vf, beanList)
-		return err
+		argBean, err := a.getOwnedBeanInstance(owner, argBd, dependencies)
+		if err != nil {
+			return bean, err
+		}
+		arg, err := ReflectUtils.ConvertTo(argBean, argType)
+		if err != nil {
+			err = replacedBeanError(argBd, argBean, argType, err)
+			return bean, errors.New("Bean[" + bd.Name + "]工厂方法参数[" + strconv.Itoa(i) + "](" + argType.String() + ")类型转换失败: " + err.Error())
+		}
+		args = append(args, arg)
 	}
 
-	// 非 Slice 类型
-	var refBd *BeanDefinition
-	if len(inject.Name) > 0 {
-		refBd = a.container[inject.Name]
-	} else {
-		refBd, err = a.getPrimaryBeanDefinitionOfType(tf.Type)
+	rets := bd.Factory.Call(args)
+	if len(rets) > 1 && !rets[1].IsNil() {
+		return bean, fmt.Errorf("Bean[%s]工厂方法执行失败: %w", bd.Name, rets[1].Interface().(error))
+	}
+	if rets[0].IsNil() {
+		return bean, errors.New("Bean[" + bd.Name + "]工厂方法返回了 nil")
+	}
+	return rets[0], nil
+}
+
+func (a *Application) doInitOrDestroy(ctx context.Context, bd *BeanDefinition, init bool) (err error) {
+	fn, method := bd.InitFn, "Init"
+	if !init {
+		fn, method = bd.DestroyFn, "Destroy"
+	}
+	return a.invokeLifecycleMethod(ctx, fn, method, []*dependencyStep{{bd: bd}})
+}
+
+/**
+执行初始化 或者 destroy 方法，参数支持：
+1. context.Context：Init 方法为带有启动超时时间（sparrow.startup.timeout）的 context，Destroy 方法为带有优雅退出超时时间的 context
+2. env.Environment、*Application
+3. 其他类型按照类型从容器中获取 Bean（存在多个的时候使用 Primary）
+方法最后一个返回值是 error 的话，返回的错误会作为执行结果返回，dependencies 最后一个为当前执行方法的 Bean
+*/
+func (a *Application) invokeLifecycleMethod(ctx context.Context, fn reflect.Value, method string, dependencies []*dependencyStep) (err error) {
+	if !fn.IsValid() {
+		return
+	}
+	ft := fn.Type()
+	current := dependencies[len(dependencies)-1]
+	args := make([]reflect.Value, 0, ft.NumIn())
+	for i := 0; i < ft.NumIn(); i++ {
+		argType := ft.In(i)
+		if arg, ok := a.lifecycleBuiltinArg(ctx, argType); ok {
+			args = append(args, arg)
+			continue
+		}
+		current.field = method + ".arg" + strconv.Itoa(i)
+		owner, argBd, err := a.lookupPrimaryBeanDefinitionOfType(argType, "")
 		if err != nil {
-			refBd = nil
+			return errors.New(method + " 方法参数[" + strconv.Itoa(i) + "](" + argType.String() + ")无法解析: " + err.Error())
+		}
+		argBean, err := a.getOwnedBeanInstance(owner, argBd, dependencies)
+		if err != nil {
+			return err
+		}
+		arg, err := ReflectUtils.ConvertTo(argBean, argType)
+		if err != nil {
+			err = replacedBeanError(argBd, argBean, argType, err)
+			return errors.New(method + " 方法参数[" + strconv.Itoa(i) + "](" + argType.String() + ")类型转换失败: " + err.Error())
+		}
+		args = append(args, arg)
+	}
+
+	rets := fn.Call(args)
+	if len(rets) > 0 && ft.Out(len(rets)-1) == ReflectUtils.ErrorType && !rets[len(rets)-1].IsNil() {
+		return rets[len(rets)-1].Interface().(error)
+	}
+	return
+}
+
+/**
+生命周期方法的内置参数：context.Context、env.Environment、*Application，不是内置参数的话返回 false
+*/
+func (a *Application) lifecycleBuiltinArg(ctx context.Context, argType reflect.Type) (arg reflect.Value, ok bool) {
+	if argType == contextType {
+		return reflect.ValueOf(ctx), true
+	}
+	if argType.Kind() == reflect.Interface && a.Environment != nil && reflect.TypeOf(a.Environment).Implements(argType) {
+		return reflect.ValueOf(a.Environment), true
+	}
+	if argType == reflect.TypeOf(a) || (argType.Kind() == reflect.Interface && reflect.TypeOf(a).Implements(argType)) {
+		return reflect.ValueOf(a), true
+	}
+	return arg, false
+}
+
+/**
+按照 @Inject 注解进行注入
+*/
+func (a *Application) wireBeanFieldByInjectAnnotation(tf reflect.StructField, vf reflect.Value, inject *annotations.InjectAnn, dependencies []*dependencyStep) (err error) {
+
+	if tf.Type.Kind() == reflect.Slice || tf.Type.Kind() == reflect.Map {
+		return a.wireBeanCollectionField(tf, vf, inject, dependencies)
+	}
+
+	// 非 Slice、Map 类型
+	beanType := tf.Type
+	if inject.Lazy {
+		if beanType.Kind() != reflect.Func || beanType.NumIn() != 0 || beanType.NumOut() < 1 || beanType.NumOut() > 2 ||
+			(beanType.NumOut() == 2 && beanType.Out(1) != ReflectUtils.ErrorType) {
+			return errors.New("lazy 注入的字段类型必须是 func() T 或者 func() (T, error): " + tf.Name + "(" + tf.Type.String() + ")")
 		}
+		beanType = beanType.Out(0)
 	}
 
+	owner, refBd := a.resolveInjectBeanDefinition(beanType, inject)
 	if nil == refBd && inject.Required {
-		err = errors.New("===========Ref Bean(ByBeanName) not found: " + inject.Name)
-		logger.Fatal(err.Error(), err)
+		err = errors.New("Ref Bean not found: " + tf.Name + "(" + tf.Type.String() + "), name: " + a.describeBeanName(inject.Name) + ", qualifier: " + inject.Qualifier)
+		logger.Error(err)
 		return err
 	}
 
@@ -497,14 +1407,123 @@ func (a *Application) wireBeanFieldByInjectAnnotation(tf reflect.StructField, vf
 		return
 	}
 
-	if !refBd.Ready {
-		err = a.wireBean(refBd, dependencies)
+	if inject.Lazy {
+		var chain []*dependencyStep
+		if owner == a {
+			chain = snapshotDependencies(dependencies)
+		}
+		return ReflectUtils.SetFieldValueByField(tf, vf, newLazyProvider(tf.Type, owner, refBd, chain))
+	}
+
+	bean, err := a.getOwnedBeanInstance(owner, refBd, dependencies)
+	if err != nil {
+		return err
+	}
+
+	return replacedBeanError(refBd, bean, tf.Type, ReflectUtils.SetFieldValueByField(tf, vf, bean))
+}
+
+/**
+按照 @Inject 的 name（或者类型）以及 qualifier 查找需要注入的 BeanDefinition，找不到的话返回 nil
+*/
+func (a *Application) resolveInjectBeanDefinition(beanType reflect.Type, inject *annotations.InjectAnn) (owner *Application, bd *BeanDefinition) {
+	if len(inject.Name) > 0 {
+		owner, bd = a.lookupBeanDefinition(inject.Name)
+		if bd != nil && !bd.HasQualifier(inject.Qualifier) {
+			return nil, nil
+		}
+		return
+	}
+	owner, bd, err := a.lookupPrimaryBeanDefinitionOfType(beanType, inject.Qualifier)
+	if err != nil {
+		return nil, nil
+	}
+	return
+}
+
+/**
+创建延迟获取 Bean 的函数，第一次调用的时候才会创建（初始化）Bean，
+providerType 为 func() (T, error) 的时候返回创建失败的错误，func() T 的话直接 panic；
+chain 为注入时的依赖链，在依赖链上的 Bean 创建完成之前（如：Init 方法中）调用的话，会沿用其中仍在创建的单例 Bean 检查循环依赖，
+避免等待自己正在创建的 Bean
+*/
+func newLazyProvider(providerType reflect.Type, owner *Application, bd *BeanDefinition, chain []*dependencyStep) reflect.Value {
+	beanType := providerType.Out(0)
+	return reflect.MakeFunc(providerType, func(args []reflect.Value) (results []reflect.Value) {
+		bean, err := owner.getBeanInstance(bd, owner.creatingDependencies(chain))
+		var value reflect.Value
+		if err == nil {
+			value, err = ReflectUtils.ConvertTo(bean, beanType)
+		}
+		if err != nil {
+			if providerType.NumOut() < 2 {
+				panic(err)
+			}
+			return []reflect.Value{reflect.Zero(beanType), reflect.ValueOf(&err).Elem()}
+		}
+		if providerType.NumOut() < 2 {
+			return []reflect.Value{value}
+		}
+		return []reflect.Value{value, reflect.Zero(ReflectUtils.ErrorType)}
+	})
+}
+
+/**
+Slice、Map 类型注入：注入所有匹配元素类型（以及限定标签）的 Bean，Slice 按照 Order 从大到小排序，Map 的 key 为 beanName
+*/
+func (a *Application) wireBeanCollectionField(tf reflect.StructField, vf reflect.Value, inject *annotations.InjectAnn, dependencies []*dependencyStep) (err error) {
+	et := tf.Type.Elem() // 元素类型
+	if tf.Type.Kind() == reflect.Map && tf.Type.Key().Kind() != reflect.String {
+		return errors.New("Map 类型注入的 key 必须是 string（beanName）: " + tf.Name + "(" + tf.Type.String() + ")")
+	}
+	bds := a.getQualifiedBeanDefinitionsOfType(et, inject.Qualifier)
+	if len(bds) < 1 {
+		if inject.Required {
+			err = errors.New("Ref Beans(ByBeanType) not found: " + tf.Name + "(" + et.String() + "), qualifier: " + inject.Qualifier)
+			logger.Error(err)
+			return err
+		}
+		return // 没有强制要求
+	}
+
+	bdList := make([]*BeanDefinition, 0)
+	beans := make(map[*BeanDefinition]reflect.Value)
+	for _, bd := range bds {
+		// 先处理依赖问题
+		bean, err := a.getBeanInstance(bd, dependencies)
 		if err != nil {
 			return err
 		}
+		element, err := ReflectUtils.ConvertTo(bean, et)
+		if err != nil || !element.IsValid() {
+			return err
+		}
+		bdList = append(bdList, bd)
+		beans[bd] = element
 	}
 
-	return ReflectUtils.SetFieldValueByField(tf, vf, refBd.Bean)
+	if tf.Type.Kind() == reflect.Map {
+		beanMap := reflect.MakeMapWithSize(tf.Type, len(bdList))
+		for _, bd := range bdList {
+			beanMap.SetMapIndex(reflect.ValueOf(bd.Name).Convert(tf.Type.Key()), beans[bd])
+		}
+		return ReflectUtils.SetFieldValueByField(tf, vf, beanMap)
+	}
+
+	// 排序
+	a.lock.RLock()
+	sort.Slice(bdList, func(i, j int) bool {
+		o1 := bdList[i].Order
+		o2 := bdList[j].Order
+		return o2 < o1
+	})
+	a.lock.RUnlock()
+
+	beanList := reflect.New(tf.Type).Elem()
+	for _, bd := range bdList {
+		beanList = reflect.Append(beanList, beans[bd])
+	}
+	return ReflectUtils.SetFieldValueByField(tf, vf, beanList)
 }
 
 func (a *Application) wireBeanFieldByValueAnnotation(valueAnn *annotations.ValueAnn, fieldType reflect.StructField, fieldValue reflect.Value) (err error) {
@@ -0,0 +1,291 @@
+package sparrow
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/xkgo/sparrow/env"
+	"syscall"
+	"testing"
+	"time"
+)
+
+type OrderRepo struct {
+	table string
+}
+
+type OrderService struct {
+	repo *OrderRepo
+}
+
+func NewOrderService(repo *OrderRepo) (*OrderService, error) {
+	if repo == nil {
+		return nil, errors.New("repo is required")
+	}
+	return &OrderService{repo: repo}, nil
+}
+
+func newTestApplication() *Application {
+	a := NewApplication()
+	a.Environment = env.New(env.ConfigDirs("./testdata"))
+	return a
+}
+
+func TestApplication_RegisterFactory(t *testing.T) {
+	a := newTestApplication()
+	a.RegisterFactory(NewOrderService, "", true)
+	a.RegisterFactory(func() *OrderRepo {
+		return &OrderRepo{table: "t_order"}
+	}, "orderRepo", true)
+
+	assert.Nil(t, a.init())
+
+	service, ok := a.GetBeanByName("OrderService").(*OrderService)
+	assert.True(t, ok)
+	assert.Equal(t, "t_order", service.repo.table)
+	assert.Same(t, a.GetBeanByName("orderRepo"), service.repo)
+}
+
+func TestApplication_RegisterFactoryError(t *testing.T) {
+	a := newTestApplication()
+	factoryErr := errors.New("invalid config")
+	a.RegisterFactory(func() (*OrderRepo, error) {
+		return nil, factoryErr
+	}, "orderRepo", true)
+	a.RegisterFactory(NewOrderService, "", true)
+
+	err := a.init()
+	assert.NotNil(t, err)
+	assert.True(t, errors.Is(err, factoryErr))
+}
+
+var destroyed []string
+
+type ConnPool struct {
+}
+
+func (p *ConnPool) Destroy() {
+	destroyed = append(destroyed, "ConnPool")
+}
+
+type Consumer struct {
+	pool *ConnPool `@Inject:"required=true"`
+}
+
+func (c *Consumer) Destroy() {
+	destroyed = append(destroyed, "Consumer")
+	panic("consumer close failed")
+}
+
+type Scheduler struct {
+	consumer *Consumer `@Inject:"required=true"`
+}
+
+func (s *Scheduler) Destroy() {
+	destroyed = append(destroyed, "Scheduler")
+}
+
+func TestApplication_DestroyBeans(t *testing.T) {
+	destroyed = nil
+	a := newTestApplication()
+	a.RegisterBean(&Scheduler{}, "", true)
+	a.RegisterBean(&ConnPool{}, "", true)
+	a.RegisterBean(&Consumer{}, "", true)
+
+	assert.Nil(t, a.init())
+
+	err := a.destroyBeans()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "Consumer")
+	assert.Equal(t, []string{"Scheduler", "Consumer", "ConnPool"}, destroyed)
+}
+
+func TestApplication_GracefulShutdown(t *testing.T) {
+	destroyed = nil
+	a := NewApplication()
+	a.RegisterBean(&ConnPool{}, "", true)
+
+	cancelled := false
+	err := a.Run(env.New(env.ConfigDirs("./testdata")), WithContextRunner(func(ctx context.Context, a *Application) (err error) {
+		a.Shutdown()
+		<-ctx.Done()
+		cancelled = true
+		return nil
+	}))
+
+	assert.Nil(t, err)
+	assert.True(t, cancelled)
+	assert.Equal(t, []string{"ConnPool"}, destroyed)
+}
+
+func TestApplication_ContextRunnerError(t *testing.T) {
+	destroyed = nil
+	a := NewApplication()
+	a.RegisterBean(&ConnPool{}, "", true)
+
+	runnerErr := errors.New("listen failed")
+	err := a.Run(env.New(env.ConfigDirs("./testdata")), WithContextRunner(func(ctx context.Context, a *Application) (err error) {
+		return runnerErr
+	}))
+
+	assert.Equal(t, runnerErr, err)
+	assert.Equal(t, []string{"ConnPool"}, destroyed)
+}
+
+func TestApplication_SignalDuringRunner(t *testing.T) {
+	destroyed = nil
+	a := NewApplication()
+	a.RegisterBean(&ConnPool{}, "", true)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- a.Run(env.New(env.ConfigDirs("./testdata")), WithRunner(func(a *Application) error {
+			// 模拟阻塞的 Runner（例如 HTTP 服务），程序退出时通过 context 结束
+			go func() {
+				time.Sleep(100 * time.Millisecond)
+				_ = syscall.Kill(syscall.Getpid(), syscall.SIGTERM)
+			}()
+			<-a.Context().Done()
+			return nil
+		}))
+	}()
+
+	select {
+	case err := <-done:
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"ConnPool"}, destroyed)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Runner 执行期间收到 SIGTERM 之后 Run 没有返回")
+	}
+}
+
+func TestApplication_IndependentInstances(t *testing.T) {
+	global := app
+	defer func() { app = global }()
+	app = nil
+	first := NewApplication()
+	first.RegisterBean(&OrderRepo{table: "t_first"}, "orderRepo", true)
+	second := NewApplication()
+	second.RegisterBean(&OrderRepo{table: "t_second"}, "orderRepo", true)
+
+	assert.Equal(t, 0, first.RunOnce(env.New(env.ConfigDirs("./testdata")), WithName("first")))
+	assert.Equal(t, 0, second.RunOnce(env.New(env.ConfigDirs("./testdata")), WithName("second")))
+
+	assert.Nil(t, app)
+	assert.Equal(t, "first", first.Name)
+	assert.Equal(t, "second", second.Name)
+	assert.Equal(t, "t_first", first.GetBeanByName("orderRepo").(*OrderRepo).table)
+	assert.Equal(t, "t_second", second.GetBeanByName("orderRepo").(*OrderRepo).table)
+}
+
+type PoolClient struct {
+	pool *ConnPool `@Inject:"required=true"`
+}
+
+func TestApplication_ChildApplication(t *testing.T) {
+	parent := newTestApplication()
+	parent.Environment.GetPropertySources().AddLast(env.NewMapPropertySource("parent", map[string]string{"parent.only": "yes"}))
+	parent.RegisterBean(&OrderRepo{table: "t_order"}, "orderRepo", true)
+	parent.RegisterBean(&ConnPool{}, "", true)
+	assert.Nil(t, parent.init())
+
+	child := NewChildApplication(parent)
+	child.RegisterFactory(NewOrderService, "", true)
+	child.RegisterBean(&PoolClient{}, "", true)
+	assert.Equal(t, 0, child.RunOnce(env.New(env.ConfigDirs("./testdata"))))
+
+	assert.Same(t, parent, child.Parent())
+	assert.Same(t, parent.GetBeanByName("orderRepo"), child.GetBeanByName("orderRepo"))
+	assert.Same(t, parent.GetBeanByName("orderRepo"), child.GetBeanByName("OrderService").(*OrderService).repo)
+	assert.Same(t, parent.GetBeanByName("ConnPool"), child.GetBeanByName("PoolClient").(*PoolClient).pool)
+	assert.Nil(t, parent.GetBeanByName("PoolClient"))
+
+	pool, err := child.GetBeanByType((*ConnPool)(nil))
+	assert.Nil(t, err)
+	assert.Same(t, parent.GetBeanByName("ConnPool"), pool)
+
+	value, _ := child.Environment.GetProperty("parent.only")
+	assert.Equal(t, "yes", value)
+}
+
+type DataSource interface {
+	Url() string
+}
+
+type MysqlDataSource struct {
+	url string
+}
+
+func (d *MysqlDataSource) Url() string {
+	return d.url
+}
+
+type DataSourceRouter struct {
+	Primary     DataSource            `@Inject:"required=true,qualifier=primary"`
+	Replica     DataSource            `@Inject:"required=true,qualifier=replica"`
+	Replicas    []DataSource          `@Inject:"qualifier=replica"`
+	DataSources map[string]DataSource `@Inject:"required=true"`
+}
+
+func TestApplication_MapAndQualifierInject(t *testing.T) {
+	a := newTestApplication()
+	a.RegisterBean(&MysqlDataSource{url: "master"}, "masterDataSource", false, WithQualifiers("primary"))
+	a.RegisterBean(&MysqlDataSource{url: "slave1"}, "slave1DataSource", false, WithQualifiers("replica", "readonly"))
+	a.RegisterBean(&MysqlDataSource{url: "slave2"}, "slave2DataSource", true, WithQualifiers("replica"))
+	a.RegisterBean(&DataSourceRouter{}, "", true)
+
+	assert.Nil(t, a.init())
+
+	router := a.GetBeanByName("DataSourceRouter").(*DataSourceRouter)
+	assert.Equal(t, "master", router.Primary.Url())
+	assert.Equal(t, "slave2", router.Replica.Url())
+	assert.Len(t, router.Replicas, 2)
+	assert.Len(t, router.DataSources, 3)
+	assert.Equal(t, "slave1", router.DataSources["slave1DataSource"].Url())
+}
+
+func TestApplication_QualifierNotFound(t *testing.T) {
+	a := newTestApplication()
+	a.RegisterBean(&MysqlDataSource{url: "master"}, "masterDataSource", true, WithQualifiers("primary"))
+	a.RegisterBean(&DataSourceRouter{}, "", true)
+
+	err := a.init()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "replica")
+}
+
+type ListenProperties struct {
+	Port int `ck:"port" validate:"required,min=1,max=65535"`
+}
+
+func TestApplication_InvalidPropertiesBean(t *testing.T) {
+	a := NewApplication()
+	a.Environment = env.New(env.ConfigDirs("./testdata"), env.AppendCommandLine("--listen.port=70000"))
+	a.RegisterPropertiesBean(&ListenProperties{}, "", "listen.", true)
+
+	err := a.init()
+	assert.NotNil(t, err)
+	var validationErr *env.PropertyValidationError
+	assert.True(t, errors.As(err, &validationErr))
+	assert.Equal(t, "listen.port", validationErr.Violations[0].Key)
+}
+
+func TestApplication_GetBeanBeforeRun(t *testing.T) {
+	searchClientCreated = 0
+	a := NewApplication()
+	client := &SearchClient{endpoint: "http://search"}
+	a.RegisterBean(client, "", true)
+	properties := &ListenProperties{}
+	a.RegisterPropertiesBean(properties, "", "listen.", true)
+
+	// 容器还没有初始化（Environment 也还没有设置），直接返回注册的对象，不执行注入以及初始化
+	bean, err := a.GetBeanByType((*SearchClient)(nil))
+	assert.Nil(t, err)
+	assert.Same(t, client, bean)
+	bean, err = a.GetBeanByType((*ListenProperties)(nil))
+	assert.Nil(t, err)
+	assert.Same(t, properties, bean)
+	assert.Same(t, client, MustGet[*SearchClient](a))
+	assert.Equal(t, 0, searchClientCreated)
+	assert.Empty(t, a.initializedBeans)
+}
@@ -1,9 +1,16 @@
 package sparrow
 
+import "context"
+
 type Option func(app *Application)
 
 type Runner func(app *Application) (err error)
 
+/**
+支持 context 的 Runner，会在单独的 Goroutine 中执行，程序退出时 ctx 会被取消，Runner 需要尽快结束
+*/
+type ContextRunner func(ctx context.Context, app *Application) (err error)
+
 /**
 设置 APP 应用名称，默认会读取环境中的 sparrow.application.name
 */
@@ -22,6 +29,25 @@ func WithRunner(runner Runner) Option {
 	}
 }
 
+/**
+当运行环境、IOC注入都已经好了之后，在 Goroutine 中执行，适用于需要长时间运行的 Runner（如 HTTP 服务），
+收到退出信号时 ctx 会被取消，返回 error 的话程序会开始退出
+*/
+func WithContextRunner(runner ContextRunner) Option {
+	return func(app *Application) {
+		app.contextRunner = runner
+	}
+}
+
+/**
+Runner 执行完成之后直接退出（执行所有销毁处理），不再等待退出信号，适用于批处理、命令行程序
+*/
+func WithExitAfterRunner() Option {
+	return func(app *Application) {
+		app.exitAfterRunner = true
+	}
+}
+
 /**
 程序退出时候执行销毁处理
 */
@@ -3,6 +3,7 @@ package ReflectUtils
 import (
 	"github.com/xkgo/sparrow/util/ConvertUtils"
 	"reflect"
+	"time"
 )
 
 var (
@@ -22,6 +23,8 @@ var (
 	DefBool    bool    = false
 	DefRune    rune    = 0 // int32 别称
 	DefByte    byte    = 0 // uint8 别称
+
+	DefDuration time.Duration = 0
 )
 
 var (
@@ -230,4 +233,15 @@ func init() {
 		val, err = ConvertUtils.ToFloat64(value)
 		return
 	})
+
+	// time.Duration，支持 30s、1m 等格式，纯数字的话单位是纳秒
+	RegisterType(DefDuration, func(value string) (val interface{}, err error) {
+		if value == "" {
+			return time.Duration(0), nil
+		}
+		if nanos, err := ConvertUtils.ToInt64(value); err == nil {
+			return time.Duration(nanos), nil
+		}
+		return time.ParseDuration(ConvertUtils.TrimBlank(value))
+	})
 }